@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io/fs"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -90,10 +91,22 @@ type Application struct {
 	keyboardProfileSources map[string]profile.SourceConfig
 	// The cached audio files for the current profile.
 	keyboardProfileAudioCache map[string]*audio.Audio
+	// The profiles mixed with the current keyboard profile
+	keyboardBlendConfig appProfileBlendConfig
 	// The keys that are currently down
 	keyboardKeysDown []key.Key
 	// Lock for the keyboard keys down
 	keyboardKeysDownLock sync.RWMutex
+	// How sounds are played for key repeat events
+	keyRepeatConfig appKeyRepeatConfig
+	// The recorded presses of the keys that are currently down, by key code
+	keyboardKeysPressed map[uint32]keyPress
+	// The time of the most recent key press
+	keyboardLastPressAt time.Time
+	// The pending hold sounds for keys that are currently down, by key code
+	keyboardHoldTimers map[uint32]holdTimers
+	// Lock for the keyboard key press state
+	keyboardPressStateLock sync.Mutex
 
 	oskHelperLock           sync.RWMutex
 	oskHelperEnabled        bool
@@ -113,6 +126,8 @@ type Application struct {
 	mouseProfileSources map[string]profile.SourceConfig
 	// The cached audio files for the current profile.
 	mouseProfileAudioCache map[string]*audio.Audio
+	// The profiles mixed with the current mouse profile
+	mouseBlendConfig appProfileBlendConfig
 
 	// The application focus detector
 	focusDetector rules.FocusDetector
@@ -120,6 +135,12 @@ type Application struct {
 	currentProfiles rules.Profiles
 	// Lock for the current profiles
 	currentProfilesLock sync.RWMutex
+	// The default profiles on Linux, which has no application rules
+	linuxDefaultProfiles rules.Profiles
+	// The rotation of the default profiles
+	profileRotationConfig appProfileRotationConfig
+	// Stops watching the profiles directory for changes
+	stopProfileWatcher context.CancelFunc
 
 	// Resolved path of this process (for matching focus events to the desktop app).
 	selfExecutablePath string
@@ -135,7 +156,12 @@ type Application struct {
 // NewApp creates a new app. It initializes the audio player, keyboard listener, mouse listener, and focus detector.
 // It also loads the profiles and rules from the configuration directory.
 func NewApp(cfgDir string) (*Application, error) {
-	profile.SetProfilesDir(filepath.Join(cfgDir, "profiles"))
+	// The user's own profiles shadow system-wide profiles with the same ID.
+	profile.DefaultStore().SetLayers(append(
+		[]profile.Layer{{Name: "user", Dir: filepath.Join(cfgDir, "profiles")}},
+		systemProfileLayers()...,
+	)...)
+	profile.SetSampleLibraryDir(filepath.Join(cfgDir, "samples"))
 
 	err := profile.LoadProfiles()
 	if err != nil {
@@ -151,12 +177,18 @@ func NewApp(cfgDir string) (*Application, error) {
 		slog.Warn("application rules disabled", "error", err)
 	}
 
+	// Rules written before profiles had stable IDs refer to profiles by name.
+	err = rules.RewriteProfileReferences(profile.ResolveProfileID)
+	if err != nil {
+		slog.Warn("failed to migrate profile references in rules", "error", err)
+	}
+
 	err = hotkeys.LoadHotKeys(cfgDir)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load hotkeys: %w", err)
 	}
 
-	defaultProfiles := rules.GetDefaultProfiles()
+	defaultProfiles := FillDeskProfiles(rules.GetDefaultProfiles())
 
 	var (
 		keyboardProfile *profile.Profile
@@ -164,27 +196,27 @@ func NewApp(cfgDir string) (*Application, error) {
 	)
 
 	if defaultProfiles.Keyboard != nil {
-		kbp, ok := profile.FindProfileByName(*defaultProfiles.Keyboard)
+		kbp, ok := profile.FindProfile(*defaultProfiles.Keyboard)
 		if !ok {
 			return nil, fmt.Errorf("failed to find default keyboard profile")
 		}
 		if kbp == nil {
 			return nil, fmt.Errorf("failed to find default keyboard profile")
 		}
-		if kbp.Details.DeviceType != profile.DeviceTypeKeyboard {
+		if !kbp.SupportsKeyboard() {
 			return nil, fmt.Errorf("default keyboard profile is not a keyboard profile")
 		}
 		keyboardProfile = kbp
 	}
 	if defaultProfiles.Mouse != nil {
-		mmp, ok := profile.FindProfileByName(*defaultProfiles.Mouse)
+		mmp, ok := profile.FindProfile(*defaultProfiles.Mouse)
 		if !ok {
 			return nil, fmt.Errorf("failed to find default mouse profile")
 		}
 		if mmp == nil {
 			return nil, fmt.Errorf("failed to find default mouse profile")
 		}
-		if mmp.Details.DeviceType != profile.DeviceTypeMouse {
+		if !mmp.SupportsMouse() {
 			return nil, fmt.Errorf("default mouse profile is not a mouse profile")
 		}
 		mouseProfile = mmp
@@ -209,17 +241,27 @@ func NewApp(cfgDir string) (*Application, error) {
 	}
 
 	kbsApp := &Application{
-		rootDir:          cfgDir,
-		enabled:          false,
-		audioPlayer:      audio.GetAudioPlayer(),
-		keyboardListener: listener.NewKeyboardListener(),
-		mouseListener:    listener.NewMouseListener(),
-		focusDetector:    rules.NewFocusDetector(),
-		oskHelper:        oskHelper,
-		oskHelperConfig:  &defaultOSKConfig,
-		currentProfiles:  defaultProfiles,
-		keyboardVolume:   1.0,
-		mouseVolume:      1.0,
+		rootDir:              cfgDir,
+		enabled:              false,
+		audioPlayer:          audio.GetAudioPlayer(),
+		keyboardListener:     listener.NewKeyboardListener(),
+		mouseListener:        listener.NewMouseListener(),
+		focusDetector:        rules.NewFocusDetector(),
+		oskHelper:            oskHelper,
+		oskHelperConfig:      &defaultOSKConfig,
+		currentProfiles:      defaultProfiles,
+		linuxDefaultProfiles: defaultProfiles,
+		keyboardVolume:       1.0,
+		mouseVolume:          1.0,
+		keyRepeatConfig: appKeyRepeatConfig{
+			Mode:    KeyRepeatModeOff,
+			MaxRate: DefaultKeyRepeatMaxRate,
+		},
+		keyboardBlendConfig: appProfileBlendConfig{Weight: 1},
+		mouseBlendConfig:    appProfileBlendConfig{Weight: 1},
+		profileRotationConfig: appProfileRotationConfig{
+			Config: RotationConfig{Mode: RotationModeOff, IntervalMinutes: DefaultRotationIntervalMinutes},
+		},
 	}
 
 	if exe, err := os.Executable(); err == nil {
@@ -247,6 +289,11 @@ func NewApp(cfgDir string) (*Application, error) {
 	kbsApp.setKeyboardProfile(keyboardProfile)
 	kbsApp.setMouseProfile(mouseProfile)
 
+	// Reload profiles when they are edited on disk, until the app is closed.
+	watcherCtx, stopProfileWatcher := context.WithCancel(context.Background())
+	kbsApp.stopProfileWatcher = stopProfileWatcher
+	go kbsApp.watchProfiles(watcherCtx)
+
 	registerHotKeyDelegate()
 	registerToggleMuteAllHotKeyHandler(kbsApp)
 	registerToggleMuteKeyboardHotKeyHandler(kbsApp)
@@ -264,6 +311,9 @@ func NewApp(cfgDir string) (*Application, error) {
 	registerIncreaseVolumeMouseHotKeyHandler(kbsApp)
 	registerDecreaseVolumeMouseHotKeyHandler(kbsApp)
 	registerToggleOSKHelpersHandler(kbsApp)
+	registerRotateProfilesAllHotKeyHandler(kbsApp)
+	registerRotateProfilesKeyboardHotKeyHandler(kbsApp)
+	registerRotateProfilesMouseHotKeyHandler(kbsApp)
 
 	return kbsApp, nil
 }
@@ -349,6 +399,9 @@ func (m *Application) Disable() error {
 	// Wait for the event worker goroutine to finish
 	m.eventWorkerWg.Wait()
 
+	// Keys that are down will not be released while disabled.
+	m.resetKeyPressState()
+
 	// Clean up context
 	m.listenerCtx = nil
 	m.listenerCancel = nil
@@ -357,6 +410,23 @@ func (m *Application) Disable() error {
 	return nil
 }
 
+// Close stops the app if it is running, along with the profile watcher and the profile rotation timer. The app
+// must not be used after it is closed.
+func (m *Application) Close() {
+	if err := m.Disable(); err != nil && !errors.Is(err, ErrNotEnabled) {
+		slog.Error("failed to disable app", "error", err)
+	}
+
+	m.stopProfileWatcher()
+
+	m.profileRotationConfig.Lock.Lock()
+	if m.profileRotationConfig.stopTimer != nil {
+		m.profileRotationConfig.stopTimer()
+		m.profileRotationConfig.stopTimer = nil
+	}
+	m.profileRotationConfig.Lock.Unlock()
+}
+
 // IsEnabled returns true if the app is enabled.
 func (m *Application) IsEnabled() bool {
 	m.enabledLock.RLock()
@@ -400,7 +470,11 @@ func (m *Application) setDefaultProfilesWindows(profiles rules.Profiles) error {
 }
 
 func (m *Application) setDefaultProfilesLinux(profiles rules.Profiles) error {
-	m.updateProfiles(profiles)
+	m.currentProfilesLock.Lock()
+	m.linuxDefaultProfiles = profiles
+	m.currentProfilesLock.Unlock()
+
+	m.updateProfiles(m.applyRotationOverrides(profiles))
 
 	return nil
 }
@@ -418,54 +492,13 @@ func (m *Application) setKeyboardProfile(p *profile.Profile) error {
 		return nil
 	}
 
-	if p.Details.DeviceType != profile.DeviceTypeKeyboard {
+	if !p.SupportsKeyboard() {
 		return fmt.Errorf("profile is not a keyboard profile: %s", p.Details.DeviceType)
 	}
 
-	// Loud sources
-	profileSources := make(map[string]profile.SourceConfig, len(p.Sources))
-	audioFiles := make([]string, 0)
-	for _, source := range p.Sources {
-		sourceConfig, err := source.GetSourceConfig()
-		if err != nil {
-			return fmt.Errorf(
-				"failed to get source config for source %s: %w", source.ID, err)
-		}
-
-		profileSources[source.ID] = sourceConfig
-
-		if sourceConfig.Press != nil {
-			audioFiles = append(audioFiles, *sourceConfig.Press)
-		}
-
-		if sourceConfig.Release != nil {
-			audioFiles = append(audioFiles, *sourceConfig.Release)
-		}
-
-		audioFiles = lo.Uniq(audioFiles)
-	}
-
-	// Load audio files
-	audioCache := make(map[string]*audio.Audio, len(audioFiles))
-	for _, fileName := range audioFiles {
-		audioFormat, err := audio.AudioFormatForFile(fileName)
-		if err != nil {
-			return fmt.Errorf("failed to get audio format for file %s: %w", fileName, err)
-		}
-
-		filePath := filepath.Join(p.Location, fileName)
-		audioFile, err := os.Open(filePath)
-		if err != nil {
-			return fmt.Errorf("failed to open audio file %s: %w", filePath, err)
-		}
-		defer audioFile.Close()
-
-		audio, err := audio.NewAudio(audioFormat, audioFile)
-		if err != nil {
-			return fmt.Errorf("failed to load audio file %s: %w", filePath, err)
-		}
-
-		audioCache[fileName] = audio
+	profileSources, audioCache, err := loadProfileAudio(p)
+	if err != nil {
+		return err
 	}
 
 	m.keyboardProfile = p
@@ -488,61 +521,89 @@ func (m *Application) setMouseProfile(p *profile.Profile) error {
 		return nil
 	}
 
-	if p.Details.DeviceType != profile.DeviceTypeMouse {
+	if !p.SupportsMouse() {
 		return fmt.Errorf("profile is not a mouse profile: %s", p.Details.DeviceType)
 	}
 
+	profileSources, audioCache, err := loadProfileAudio(p)
+	if err != nil {
+		return err
+	}
+
+	m.mouseProfile = p
+	m.mouseProfileSources = profileSources
+	m.mouseProfileAudioCache = audioCache
+
+	return nil
+}
+
+// loadProfileAudio loads the sources of a profile and the audio files that they reference into memory. The
+// audio files of the returned sources are referenced by their full path, which is the key of the audio cache.
+// Audio files are read through the file system that each source is stored in, so profiles loaded from embedded
+// file systems or archives play the same way as profiles on disk.
+func loadProfileAudio(p *profile.Profile) (map[string]profile.SourceConfig, map[string]*audio.Audio, error) {
 	// Load sources
 	profileSources := make(map[string]profile.SourceConfig, len(p.Sources))
-	audioFiles := make([]string, 0)
+	// The functions that open each audio file, by full path
+	audioFiles := make(map[string]func() (fs.File, error))
 	for _, source := range p.Sources {
 		sourceConfig, err := source.GetSourceConfig()
 		if err != nil {
-			return fmt.Errorf(
+			return nil, nil, fmt.Errorf(
 				"failed to get source config for source %s: %w", source.ID, err)
 		}
 
-		profileSources[source.ID] = sourceConfig
-
-		if sourceConfig.Press != nil {
-			audioFiles = append(audioFiles, *sourceConfig.Press)
+		// Sources inherited from a parent profile live in the parent's directory,
+		// so audio files are referenced by their full path.
+		resolveFiles := func(files *profile.SourceConfig) {
+			for _, file := range []**string{
+				&files.Press,
+				&files.Release,
+				&files.Repeat,
+				&files.Hold,
+				&files.ReleaseLong,
+			} {
+				if *file != nil {
+					name := **file
+					*file = lo.ToPtr(p.SourceFilePath(source, name))
+					audioFiles[**file] = func() (fs.File, error) {
+						return p.OpenSourceFile(source, name)
+					}
+				}
+			}
 		}
 
-		if sourceConfig.Release != nil {
-			audioFiles = append(audioFiles, *sourceConfig.Release)
+		resolveFiles(&sourceConfig)
+		for i := range sourceConfig.Layers {
+			resolveFiles(&sourceConfig.Layers[i].Files)
 		}
 
-		audioFiles = lo.Uniq(audioFiles)
+		profileSources[source.ID] = sourceConfig
 	}
 
 	// Load audio files
 	audioCache := make(map[string]*audio.Audio, len(audioFiles))
-	for _, fileName := range audioFiles {
-		audioFormat, err := audio.AudioFormatForFile(fileName)
+	for filePath, open := range audioFiles {
+		audioFormat, err := audio.AudioFormatForFile(filePath)
 		if err != nil {
-			return fmt.Errorf("failed to get audio format for file %s: %w", fileName, err)
+			return nil, nil, fmt.Errorf("failed to get audio format for file %s: %w", filePath, err)
 		}
 
-		filePath := filepath.Join(p.Location, fileName)
-		audioFile, err := os.Open(filePath)
+		audioFile, err := open()
 		if err != nil {
-			return fmt.Errorf("failed to open audio file %s: %w", filePath, err)
+			return nil, nil, fmt.Errorf("failed to open audio file %s: %w", filePath, err)
 		}
 		defer audioFile.Close()
 
 		audio, err := audio.NewAudio(audioFormat, audioFile)
 		if err != nil {
-			return fmt.Errorf("failed to load audio file %s: %w", filePath, err)
+			return nil, nil, fmt.Errorf("failed to load audio file %s: %w", filePath, err)
 		}
 
-		audioCache[fileName] = audio
+		audioCache[filePath] = audio
 	}
 
-	m.mouseProfile = p
-	m.mouseProfileSources = profileSources
-	m.mouseProfileAudioCache = audioCache
-
-	return nil
+	return profileSources, audioCache, nil
 }
 
 // OSKHelperStateChangedDelegate is called when OSK helper state changes
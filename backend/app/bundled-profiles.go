@@ -0,0 +1,25 @@
+package app
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+)
+
+// RestoreBundledProfile replaces a bundled profile with its version in bundledDir, discarding the changes made
+// to it, and reloads the active keyboard and mouse profiles and blend layers that use it. The installed bundled
+// profiles are recorded in the file at statePath, as for profile.SyncBundledProfiles.
+func (m *Application) RestoreBundledProfile(bundledDir string, statePath string, id string) error {
+	profilesDir := filepath.Join(m.rootDir, "profiles")
+	if err := profile.RestoreBundledProfile(bundledDir, profilesDir, statePath, id); err != nil {
+		return err
+	}
+
+	if err := profile.LoadProfiles(); err != nil {
+		return fmt.Errorf("failed to reload profiles: %w", err)
+	}
+
+	m.reloadChangedProfiles([]string{filepath.Join(profilesDir, filepath.Base(id))})
+	return nil
+}
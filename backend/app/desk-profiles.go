@@ -0,0 +1,27 @@
+package app
+
+import (
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/rules"
+)
+
+// FillDeskProfiles returns a copy of profiles in which a device without a profile uses the desk profile
+// selected for the other device, so that a desk profile selected once applies to both the keyboard and the
+// mouse. A device that has its own profile selected keeps it.
+func FillDeskProfiles(profiles rules.Profiles) rules.Profiles {
+	if profiles.Keyboard != nil && profiles.Mouse == nil && isDeskProfile(*profiles.Keyboard) {
+		ref := *profiles.Keyboard
+		profiles.Mouse = &ref
+	} else if profiles.Mouse != nil && profiles.Keyboard == nil && isDeskProfile(*profiles.Mouse) {
+		ref := *profiles.Mouse
+		profiles.Keyboard = &ref
+	}
+
+	return profiles
+}
+
+// isDeskProfile returns true if ref refers to a loaded desk profile.
+func isDeskProfile(ref string) bool {
+	p, ok := profile.FindProfile(ref)
+	return ok && p.Details.DeviceType == profile.DeviceTypeDesk
+}
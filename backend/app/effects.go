@@ -2,9 +2,11 @@ package app
 
 import (
 	"log/slog"
+	"math/rand"
 	"sync"
 
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/audio"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
 )
 
 // maxPlaybackVolume is the maximum linear gain for keyboard/mouse volume (2.0 = 200%).
@@ -46,6 +48,26 @@ type appDopplerConfig struct {
 	Lock    sync.RWMutex
 }
 
+// applySourceAdjustments applies the gain, pitch and volume jitter of a profile source on top of the global
+// effects. The pitch of the source shifts the global pitch range, and its gain and jitter scale the global volume.
+func applySourceAdjustments(fx *audio.EffectsConfig, adjustments profile.SourceAdjustments) {
+	pitch := adjustments.PitchSemitones
+	if !pitch.IsZero() {
+		offset := pitch[0] + rand.Float64()*(pitch[1]-pitch[0])
+		if fx.Pitch == nil {
+			fx.Pitch = &audio.PitchConfig{SemitoneRange: [2]float64{offset, offset}}
+		} else {
+			fx.Pitch.SemitoneRange[0] += offset
+			fx.Pitch.SemitoneRange[1] += offset
+		}
+	}
+
+	if fx.Volume != nil {
+		jitter := 1 + adjustments.VolumeJitter*(rand.Float64()*2-1)
+		fx.Volume.Volume *= adjustments.Gain() * jitter
+	}
+}
+
 // SetAudioPitchShift sets the pitch shift semi-tone value upper and lower bounds.
 // If either lower or upper is 0, pitch shift is disabled.
 func (m *Application) SetKeyboardAudioPitchShift(enabled bool, lower, upper float64) {
@@ -44,6 +44,8 @@ func (m *Application) focusEventWorker() {
 }
 
 func (m *Application) updateProfiles(newProfiles rules.Profiles) {
+	newProfiles = FillDeskProfiles(newProfiles)
+
 	m.currentProfilesLock.RLock()
 	diff := m.currentProfiles.Diff(newProfiles)
 	m.currentProfilesLock.RUnlock()
@@ -56,7 +58,7 @@ func (m *Application) updateProfiles(newProfiles rules.Profiles) {
 	)
 
 	if diff.ShouldUpdateKeyboard && newProfiles.Keyboard != nil {
-		newKeyboardProfile, ok = profile.FindProfileByName(*newProfiles.Keyboard)
+		newKeyboardProfile, ok = profile.FindProfile(*newProfiles.Keyboard)
 		if !ok {
 			slog.Error("application rule ignored: failed to find keyboard profile", "profile", *newProfiles.Keyboard)
 			ruleValidated = false
@@ -65,7 +67,7 @@ func (m *Application) updateProfiles(newProfiles rules.Profiles) {
 				slog.Error("application rule ignored: failed to find keyboard profile", "profile", *newProfiles.Keyboard)
 				ruleValidated = false
 			} else {
-				if newKeyboardProfile.Details.DeviceType != profile.DeviceTypeKeyboard {
+				if !newKeyboardProfile.SupportsKeyboard() {
 					slog.Error("application rule ignored: keyboard profile is not a keyboard profile", "profile", *newProfiles.Keyboard)
 					ruleValidated = false
 				}
@@ -73,7 +75,7 @@ func (m *Application) updateProfiles(newProfiles rules.Profiles) {
 		}
 	}
 	if ruleValidated && diff.ShouldUpdateMouse && newProfiles.Mouse != nil {
-		newMouseProfile, ok = profile.FindProfileByName(*newProfiles.Mouse)
+		newMouseProfile, ok = profile.FindProfile(*newProfiles.Mouse)
 		if !ok {
 			slog.Error("application rule ignored: failed to find mouse profile", "profile", *newProfiles.Mouse)
 			ruleValidated = false
@@ -82,7 +84,7 @@ func (m *Application) updateProfiles(newProfiles rules.Profiles) {
 				slog.Error("application rule ignored: failed to find mouse profile", "profile", *newProfiles.Mouse)
 				ruleValidated = false
 			} else {
-				if newMouseProfile.Details.DeviceType != profile.DeviceTypeMouse {
+				if !newMouseProfile.SupportsMouse() {
 					slog.Error("application rule ignored: mouse profile is not a mouse profile", "profile", *newProfiles.Mouse)
 					ruleValidated = false
 				}
@@ -5,11 +5,13 @@ import (
 	"log/slog"
 	"math/rand"
 	"strings"
+	"time"
 
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/audio"
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/hotkeys"
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/key"
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/listener/listenertypes"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
 	"github.com/samber/lo"
 )
 
@@ -38,11 +40,22 @@ func (m *Application) keyboardEventWorker() {
 			// (e.g. letter after modifier release) and kept calling SetOnScreenText, canceling
 			// the delayed hide from ClearOnScreenText.
 			if m.isKeyRepeat(e) {
+				// Key repeats do not change the keys down, so only the sound is processed.
+				if m.shouldPlayKeyboard() && m.shouldPlayKeyRepeat() {
+					go m.playAudioForKeyEvent(e, keyEventContext{HeldKeys: m.heldKeys(e), Repeat: true})
+				}
 				continue
 			}
 
+			keyCtx := keyEventContext{HeldKeys: m.heldKeys(e)}
+			if e.Action == listenertypes.ActionPress {
+				keyCtx.Press = m.recordKeyPress(e.Key)
+			} else {
+				keyCtx.Press, keyCtx.HeldFor = m.recordKeyRelease(e.Key)
+			}
+
 			if m.shouldPlayKeyboard() {
-				go m.playAudioForKeyEvent(e)
+				go m.playAudioForKeyEvent(e, keyCtx)
 			}
 
 			m.updateKeyboardKeysDown(e)
@@ -86,20 +99,78 @@ func (m *Application) shouldPlayKeyboard() bool {
 	return false
 }
 
-// playAudioForKeyEvent plays the audio for a given key event.
+// heldKeys returns the keys held while a key event occurred, other than the key of the event itself.
 //
-// Before playing the audio, this function applies any configured audio effects and volume to the audio.
-func (m *Application) playAudioForKeyEvent(e listenertypes.KeyEvent) {
-	sound, err := m.getAudioForKeyEvent(e)
+// Keys are merged from keyboardKeysDown and e.ModifierKeys, since some platforms do not deliver separate
+// key events for modifiers. This must be called before the event is applied to keyboardKeysDown.
+func (m *Application) heldKeys(e listenertypes.KeyEvent) []key.Key {
+	m.keyboardKeysDownLock.RLock()
+	defer m.keyboardKeysDownLock.RUnlock()
+
+	held := make([]key.Key, 0, len(m.keyboardKeysDown)+len(e.ModifierKeys))
+	seen := map[uint32]bool{e.Key.Code: true}
+	for _, k := range lo.Flatten([][]key.Key{e.ModifierKeys, m.keyboardKeysDown}) {
+		if !seen[k.Code] {
+			seen[k.Code] = true
+			held = append(held, k)
+		}
+	}
+
+	return held
+}
+
+// keyEventContext is the state of the keyboard when a key event occurred.
+type keyEventContext struct {
+	// The keys held when the event occurred, other than the key of the event. Modifier conditions in the
+	// profile are matched against these keys.
+	HeldKeys []key.Key
+	// Whether the event is a key repeat.
+	Repeat bool
+	// The press of the key. For release events, this is zero if the press was not recorded.
+	Press keyPress
+	// How long the key was held down, for release events. This is zero if the press was not recorded.
+	HeldFor time.Duration
+}
+
+// keyEventAudio is the audio chosen for a key event.
+type keyEventAudio struct {
+	// The audio to play for the event.
+	Sound *audio.Audio
+	// The audio to play once the key has been held down for HoldThreshold, for press events.
+	Hold *audio.Audio
+	// How long the key must be held down before Hold is played.
+	HoldThreshold time.Duration
+	// The adjustments of the source that the audio was chosen from.
+	Adjustments profile.SourceAdjustments
+}
+
+// playAudioForKeyEvent plays the audio for a given key event, and schedules the hold audio of the key if it
+// has one. When profiles are blended, the audio chosen from each profile is played together.
+func (m *Application) playAudioForKeyEvent(e listenertypes.KeyEvent, keyCtx keyEventContext) {
+	sounds, err := m.getAudioForKeyEvent(e, keyCtx)
 	if err != nil {
 		slog.Error("failed to get audio for key event", "error", err)
 		return
 	}
 
-	if sound == nil {
-		return
+	for _, sound := range sounds {
+		if sound.Hold != nil {
+			m.scheduleHoldSound(e.Key, keyCtx.Press.At, sound.HoldThreshold, func() {
+				m.playKeyboardAudio(e, sound.Hold, sound.Adjustments)
+			})
+		}
+
+		if sound.Sound != nil {
+			m.playKeyboardAudio(e, sound.Sound, sound.Adjustments)
+		}
 	}
+}
 
+// playKeyboardAudio plays audio for a given key event.
+//
+// Before playing the audio, this function applies any configured audio effects and volume to the audio, followed by
+// the adjustments of the source that the audio was chosen from.
+func (m *Application) playKeyboardAudio(e listenertypes.KeyEvent, sound *audio.Audio, adjustments profile.SourceAdjustments) {
 	fx := audio.EffectsConfig{}
 
 	// Apply pitch shift effect
@@ -145,87 +216,168 @@ func (m *Application) playAudioForKeyEvent(e listenertypes.KeyEvent) {
 	}
 	m.keyboardVolumeLock.RUnlock()
 
-	err = m.audioPlayer.Play(sound, fx)
+	applySourceAdjustments(&fx, adjustments)
+
+	err := m.audioPlayer.Play(sound, fx)
 	if err != nil {
 		slog.Error("failed to play audio", "error", err)
 	}
 }
 
-// getAudioForKeyEvent gets the audio file for a given key event.
-//
-// The audio file is chosen based on the following priority:
-// 1. If the key is in the m.keyboardProfile.Keys.Other map, the audio file is chosen from the map.
-// 2. If the key is not in the m.keyboardProfile.Keys.Other map, the audio file is chosen randomly from the m.keyboardProfile.Keys.Default slice.
-// 3. If there are no audio files in the m.keyboardProfile.Keys.Other map or m.keyboardProfile.Keys.Default slice, a random souce will be selected.
-func (m *Application) getAudioForKeyEvent(event listenertypes.KeyEvent) (*audio.Audio, error) {
+// getAudioForKeyEvent gets the audio for a given key event from the active keyboard profile and from each of
+// the profiles blended with it. The gain of the audio chosen from each profile is scaled by the weight of the
+// profile in the blend. Profiles with a weight of zero are skipped.
+func (m *Application) getAudioForKeyEvent(event listenertypes.KeyEvent, keyCtx keyEventContext) ([]keyEventAudio, error) {
 	m.keyboardProfileLock.RLock()
-	defer m.keyboardProfileLock.RUnlock()
+	active := loadedProfile{
+		Profile:    m.keyboardProfile,
+		Sources:    m.keyboardProfileSources,
+		AudioCache: m.keyboardProfileAudioCache,
+	}
+	m.keyboardProfileLock.RUnlock()
 
-	if m.keyboardProfile == nil {
+	if active.Profile == nil {
 		return nil, fmt.Errorf("no profile set")
 	}
 
-	if len(m.keyboardProfile.Sources) < 1 {
-		return nil, fmt.Errorf("no sources found for keyboard profile")
+	weight, layers := m.keyboardBlendConfig.snapshot()
+
+	sounds := make([]keyEventAudio, 0, len(layers)+1)
+	if weight > 0 {
+		sound, err := active.getAudioForKeyEvent(event, keyCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		sound.Adjustments = weightAdjustments(sound.Adjustments, weight)
+		sounds = append(sounds, sound)
+	}
+
+	for _, layer := range layers {
+		if layer.Weight <= 0 {
+			continue
+		}
+
+		sound, err := layer.getAudioForKeyEvent(event, keyCtx)
+		if err != nil {
+			slog.Error("failed to get audio for key event from blended profile", "profile", layer.Profile.Details.Name, "error", err)
+			continue
+		}
+
+		sound.Adjustments = weightAdjustments(sound.Adjustments, layer.Weight)
+		sounds = append(sounds, sound)
+	}
+
+	return sounds, nil
+}
+
+// getAudioForKeyEvent gets the audio file for a given key event from a profile.
+//
+// The audio file is chosen based on the following priority:
+// 1. If an entry in the Keys.Other map of the profile with when_modifiers matches the key and all of its modifiers are held, the audio file is chosen from the entry requiring the most modifiers.
+// 2. If the key is in the Keys.Other map of the profile, by name or by a group or region selector, the audio file is chosen from the map, preferring entries that list the key by name.
+// 3. If the key is not in the Keys.Other map of the profile, the audio file is chosen randomly from the Keys.Default slice of the profile.
+// 4. If there are no audio files in the Keys.Other map of the profile or Keys.Default slice, a random souce will be selected.
+//
+// For key repeat events, the repeat audio file of the source is used if it has one, otherwise the press audio file is used.
+// For press events, the hold audio file of the source is returned alongside the press audio file. For release events after
+// the key was held down for the hold threshold of the source, the release_long audio file is used if the source has one.
+// If the source has velocity layers, the audio files are taken from the layer matching the time between the key press and
+// the previous key press.
+func (p loadedProfile) getAudioForKeyEvent(event listenertypes.KeyEvent, keyCtx keyEventContext) (keyEventAudio, error) {
+	if p.Profile == nil {
+		return keyEventAudio{}, fmt.Errorf("no profile set")
+	}
+
+	if len(p.Profile.Sources) < 1 {
+		return keyEventAudio{}, fmt.Errorf("no sources found for keyboard profile")
 	}
 
 	var sourceID string
 
-	// Check if an "other" config exists for this key.
-	if len(m.keyboardProfile.Keys.Other) > 0 {
-		for _, k := range m.keyboardProfile.Keys.Other {
-			if k.Keys == nil || len(*k.Keys) == 0 {
+	// Check if an "other" config exists for this key. Entries requiring held modifiers take precedence over
+	// plain entries, and the entry requiring the most modifiers wins. Between entries requiring the same
+	// number of modifiers, entries listing the key by name take precedence over entries selecting it with a
+	// group or region. Otherwise the last matching entry wins.
+	if len(p.Profile.Keys.Other) > 0 {
+		var (
+			match     *profile.Key
+			modifiers int
+			keyMatch  profile.KeyMatch
+		)
+		for i, k := range p.Profile.Keys.Other {
+			km := k.MatchKey(event.Key)
+			if km == profile.KeyMatchNone || !k.MatchesModifiers(keyCtx.HeldKeys) {
 				continue
 			}
 
-			if lo.ContainsBy(*k.Keys, func(key string) bool {
-				return strings.EqualFold(key, event.Key.Name) || strings.EqualFold(key, fmt.Sprintf("%d", event.Key.Code))
-			}) {
-				// Source value can either be a string or a slice of strings.
-				switch sourceValue := k.Sound.(type) {
-				case string: // Single source ID
-					sourceID = sourceValue
-				case []any: // Multiple source IDs, pick a random one.
-					sourceID = sourceValue[rand.Intn(len(sourceValue))].(string)
-				case []string: // Multiple source IDs, pick a random one.
-					sourceID = sourceValue[rand.Intn(len(sourceValue))]
-				default:
-					return nil, fmt.Errorf("invalid sound source value: %T", sourceValue)
-				}
+			if match == nil || len(k.WhenModifiers) > modifiers ||
+				(len(k.WhenModifiers) == modifiers && km >= keyMatch) {
+				match = &p.Profile.Keys.Other[i]
+				modifiers = len(k.WhenModifiers)
+				keyMatch = km
 			}
 		}
+
+		if match != nil {
+			// A single source or one of multiple sources, picked at random.
+			sourceID = match.Sound.Pick()
+		}
 	}
 
 	// Attempt to pick from the default sources, if any are configured.
 	if sourceID == "" {
-		if len(m.keyboardProfile.Keys.Default) > 0 {
-			sourceID = m.keyboardProfile.Keys.Default[rand.Intn(len(m.keyboardProfile.Keys.Default))]
-		}
+		sourceID = p.Profile.Keys.Default.Pick()
 	}
 
 	// Fall back on using a random source from the sources list.
 	if sourceID == "" {
-		sourceID = m.keyboardProfile.Sources[rand.Intn(len(m.keyboardProfile.Sources))].ID
+		sourceID = p.Profile.Sources[rand.Intn(len(p.Profile.Sources))].ID
 	}
 
-	sourceConfig, ok := m.keyboardProfileSources[sourceID]
+	sourceConfig, ok := p.Sources[sourceID]
 	if !ok {
-		return nil, fmt.Errorf("source config not found for source %s", sourceID)
+		return keyEventAudio{}, fmt.Errorf("source config not found for source %s", sourceID)
 	}
 
+	// Pick the velocity layer from how soon after the previous key press the key was pressed.
+	sourceConfig = sourceConfig.ForInterval(keyCtx.Press.Interval)
+
 	if event.Action == listenertypes.ActionRelease {
+		if sourceConfig.ReleaseLong != nil && keyCtx.HeldFor >= sourceConfig.HoldThreshold() {
+			return keyEventAudio{Sound: p.AudioCache[*sourceConfig.ReleaseLong], Adjustments: sourceConfig.Adjustments}, nil
+		}
+
 		if sourceConfig.Release != nil {
-			return m.keyboardProfileAudioCache[*sourceConfig.Release], nil
+			return keyEventAudio{Sound: p.AudioCache[*sourceConfig.Release], Adjustments: sourceConfig.Adjustments}, nil
 		}
 
-		return nil, nil
+		return keyEventAudio{}, nil
 	}
 
+	if keyCtx.Repeat {
+		if sourceConfig.Repeat != nil {
+			return keyEventAudio{Sound: p.AudioCache[*sourceConfig.Repeat], Adjustments: sourceConfig.Adjustments}, nil
+		}
+
+		if sourceConfig.Press != nil {
+			return keyEventAudio{Sound: p.AudioCache[*sourceConfig.Press], Adjustments: sourceConfig.Adjustments}, nil
+		}
+
+		return keyEventAudio{}, nil
+	}
+
+	sounds := keyEventAudio{Adjustments: sourceConfig.Adjustments}
 	if sourceConfig.Press != nil {
-		return m.keyboardProfileAudioCache[*sourceConfig.Press], nil
+		sounds.Sound = p.AudioCache[*sourceConfig.Press]
+	}
+
+	if sourceConfig.Hold != nil {
+		sounds.Hold = p.AudioCache[*sourceConfig.Hold]
+		sounds.HoldThreshold = sourceConfig.HoldThreshold()
 	}
 
-	return nil, nil
+	return sounds, nil
 }
 
 // updateKeyboardKeysDown updates the keys that are currently down.
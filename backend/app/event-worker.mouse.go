@@ -8,6 +8,7 @@ import (
 
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/audio"
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/listener/listenertypes"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
 	"github.com/samber/lo"
 )
 
@@ -60,20 +61,27 @@ func (m *Application) shouldPlayMouse() bool {
 	return false
 }
 
-// playAudioForButtonEvent plays audio for a mouse button event.
-//
-// Before playing the audio, this function applies any configured audio effects and volume to the audio.
+// playAudioForButtonEvent plays audio for a mouse button event. When profiles are blended, the audio chosen
+// from each profile is played together.
 func (m *Application) playAudioForButtonEvent(e listenertypes.ButtonEvent) {
-	sound, err := m.getAudioForButtonEvent(e)
+	sounds, err := m.getAudioForButtonEvent(e)
 	if err != nil {
 		slog.Error("failed to get audio for button event", "error", err)
 		return
 	}
 
-	if sound == nil {
-		return
+	for _, sound := range sounds {
+		if sound.Sound != nil {
+			m.playMouseAudio(sound.Sound, sound.Adjustments)
+		}
 	}
+}
 
+// playMouseAudio plays audio for a mouse button event.
+//
+// Before playing the audio, this function applies any configured audio effects and volume to the audio, followed by
+// the adjustments of the source that the audio was chosen from.
+func (m *Application) playMouseAudio(sound *audio.Audio, adjustments profile.SourceAdjustments) {
 	fx := audio.EffectsConfig{}
 
 	// Apply pitch shift effect
@@ -110,35 +118,89 @@ func (m *Application) playAudioForButtonEvent(e listenertypes.ButtonEvent) {
 	}
 	m.mouseVolumeLock.RUnlock()
 
-	err = m.audioPlayer.Play(sound, fx)
+	applySourceAdjustments(&fx, adjustments)
+
+	err := m.audioPlayer.Play(sound, fx)
 	if err != nil {
 		slog.Error("failed to play audio", "error", err)
 	}
 }
 
-// getAudioForButtonEvent gets the audio file for a given button event.
-//
-// The audio file is chosen based on the following priority:
-// 1. If the button is in the m.mouseProfile.Buttons.Other map, the audio file is chosen from the map.
-// 2. If the button is not in the m.mouseProfile.Buttons.Other map, the default audio file is chosen.
-// 3. If there are no audio files in the m.mouseProfile.Buttons.Other map or m.mouseProfile.Buttons.Default is not set, an error is returned.
-func (m *Application) getAudioForButtonEvent(event listenertypes.ButtonEvent) (*audio.Audio, error) {
+// buttonEventAudio is the audio chosen for a button event.
+type buttonEventAudio struct {
+	// The audio to play for the event.
+	Sound *audio.Audio
+	// The adjustments of the source that the audio was chosen from.
+	Adjustments profile.SourceAdjustments
+}
+
+// getAudioForButtonEvent gets the audio for a given button event from the active mouse profile and from each of
+// the profiles blended with it. The gain of the audio chosen from each profile is scaled by the weight of the
+// profile in the blend. Profiles with a weight of zero are skipped.
+func (m *Application) getAudioForButtonEvent(event listenertypes.ButtonEvent) ([]buttonEventAudio, error) {
 	m.mouseProfileLock.RLock()
-	defer m.mouseProfileLock.RUnlock()
+	active := loadedProfile{
+		Profile:    m.mouseProfile,
+		Sources:    m.mouseProfileSources,
+		AudioCache: m.mouseProfileAudioCache,
+	}
+	m.mouseProfileLock.RUnlock()
 
-	if m.mouseProfile == nil {
+	if active.Profile == nil {
 		return nil, fmt.Errorf("no mouse profile set")
 	}
 
+	weight, layers := m.mouseBlendConfig.snapshot()
+
+	sounds := make([]buttonEventAudio, 0, len(layers)+1)
+	if weight > 0 {
+		sound, adjustments, err := active.getAudioForButtonEvent(event)
+		if err != nil {
+			return nil, err
+		}
+
+		sounds = append(sounds, buttonEventAudio{Sound: sound, Adjustments: weightAdjustments(adjustments, weight)})
+	}
+
+	for _, layer := range layers {
+		if layer.Weight <= 0 {
+			continue
+		}
+
+		sound, adjustments, err := layer.getAudioForButtonEvent(event)
+		if err != nil {
+			slog.Error("failed to get audio for button event from blended profile", "profile", layer.Profile.Details.Name, "error", err)
+			continue
+		}
+
+		sounds = append(sounds, buttonEventAudio{Sound: sound, Adjustments: weightAdjustments(adjustments, layer.Weight)})
+	}
+
+	return sounds, nil
+}
+
+// getAudioForButtonEvent gets the audio file for a given button event from a profile.
+//
+// The audio file is chosen based on the following priority:
+// 1. If the button is in the Buttons.Other map of the profile, the audio file is chosen from the map.
+// 2. If the button is not in the Buttons.Other map of the profile, the audio file is chosen randomly from the Buttons.Default sources.
+// 3. If there are no audio files in the Buttons.Other map of the profile or Buttons.Default sources, a random source will be selected.
+//
+// The adjustments of the source that the audio file was chosen from are returned alongside it.
+func (p loadedProfile) getAudioForButtonEvent(event listenertypes.ButtonEvent) (*audio.Audio, profile.SourceAdjustments, error) {
+	if p.Profile == nil {
+		return nil, profile.SourceAdjustments{}, fmt.Errorf("no mouse profile set")
+	}
+
 	var sourceID string
 
-	if len(m.mouseProfile.Sources) < 1 {
-		return nil, fmt.Errorf("no sources found for mouse profile")
+	if len(p.Profile.Sources) < 1 {
+		return nil, profile.SourceAdjustments{}, fmt.Errorf("no sources found for mouse profile")
 	}
 
 	// Check if button is in the Other section
 	found := false
-	for _, b := range m.mouseProfile.Buttons.Other {
+	for _, b := range p.Profile.Buttons.Other {
 		if b.Buttons == nil || len(*b.Buttons) == 0 {
 			continue
 		}
@@ -146,17 +208,8 @@ func (m *Application) getAudioForButtonEvent(event listenertypes.ButtonEvent) (*
 		if lo.ContainsBy(*b.Buttons, func(button string) bool {
 			return strings.EqualFold(button, string(event.Button))
 		}) {
-			// Source value can either be a string or a slice of strings.
-			switch sourceValue := b.Sound.(type) {
-			case string: // Single source ID
-				sourceID = sourceValue
-			case []any: // Multiple source IDs, pick a random one.
-				sourceID = sourceValue[rand.Intn(len(sourceValue))].(string)
-			case []string: // Multiple source IDs, pick a random one.
-				sourceID = sourceValue[rand.Intn(len(sourceValue))]
-			default:
-				return nil, fmt.Errorf("invalid sound source value: %T", sourceValue)
-			}
+			// A single source or one of multiple sources, picked at random.
+			sourceID = b.Sound.Pick()
 			found = true
 			break
 		}
@@ -164,30 +217,30 @@ func (m *Application) getAudioForButtonEvent(event listenertypes.ButtonEvent) (*
 
 	// If not found in Other, use Default
 	if !found {
-		if len(m.mouseProfile.Buttons.Default) > 0 {
-			sourceID = m.mouseProfile.Buttons.Default
+		if len(p.Profile.Buttons.Default) > 0 {
+			sourceID = p.Profile.Buttons.Default.Pick()
 		} else {
 			// Use a random source.
-			sourceID = m.mouseProfile.Sources[rand.Intn(len(m.mouseProfile.Sources))].ID
+			sourceID = p.Profile.Sources[rand.Intn(len(p.Profile.Sources))].ID
 		}
 	}
 
-	sourceConfig, ok := m.mouseProfileSources[sourceID]
+	sourceConfig, ok := p.Sources[sourceID]
 	if !ok {
-		return nil, fmt.Errorf("source config not found for source %s", sourceID)
+		return nil, profile.SourceAdjustments{}, fmt.Errorf("source config not found for source %s", sourceID)
 	}
 
 	if event.Action == listenertypes.ActionRelease {
 		if sourceConfig.Release != nil {
-			return m.mouseProfileAudioCache[*sourceConfig.Release], nil
+			return p.AudioCache[*sourceConfig.Release], sourceConfig.Adjustments, nil
 		}
 
-		return nil, nil
+		return nil, profile.SourceAdjustments{}, nil
 	}
 
 	if sourceConfig.Press != nil {
-		return m.mouseProfileAudioCache[*sourceConfig.Press], nil
+		return p.AudioCache[*sourceConfig.Press], sourceConfig.Adjustments, nil
 	}
 
-	return nil, nil
+	return nil, profile.SourceAdjustments{}, nil
 }
@@ -247,6 +247,37 @@ func registerDecreaseVolumeMouseHotKeyHandler(kbsApp *Application) {
 	})
 }
 
+// =============================================================================
+// Rotate Profiles Handlers
+// =============================================================================
+
+func registerRotateProfilesAllHotKeyHandler(kbsApp *Application) {
+	hotkeys.RegisterHandler(hotkeys.HotKeyDeviceAction{
+		Device: hotkeys.HotKeyTargetDeviceAll,
+		Action: hotkeys.HotKeyActionRotateProfiles,
+	}, func(action hotkeys.HotKeyDeviceAction) error {
+		return kbsApp.RotateProfiles(true, true)
+	})
+}
+
+func registerRotateProfilesKeyboardHotKeyHandler(kbsApp *Application) {
+	hotkeys.RegisterHandler(hotkeys.HotKeyDeviceAction{
+		Device: hotkeys.HotKeyTargetDeviceKeyboard,
+		Action: hotkeys.HotKeyActionRotateProfiles,
+	}, func(action hotkeys.HotKeyDeviceAction) error {
+		return kbsApp.RotateProfiles(true, false)
+	})
+}
+
+func registerRotateProfilesMouseHotKeyHandler(kbsApp *Application) {
+	hotkeys.RegisterHandler(hotkeys.HotKeyDeviceAction{
+		Device: hotkeys.HotKeyTargetDeviceMouse,
+		Action: hotkeys.HotKeyActionRotateProfiles,
+	}, func(action hotkeys.HotKeyDeviceAction) error {
+		return kbsApp.RotateProfiles(false, true)
+	})
+}
+
 // =============================================================================
 // Other Handlers
 // =============================================================================
@@ -29,6 +29,9 @@ func cloneStrPtr(p *string) *string {
 
 func (m *Application) resolveProfilesForExecutable(focusedPath string) rules.Profiles {
 	base := rules.GetProfilesForPath(focusedPath)
+	if base.IsDefault {
+		base = m.applyRotationOverrides(base)
+	}
 	if m.executablePathsMatch(focusedPath) && base.IsDefault {
 		return m.applyInAppFocusOverrides(base)
 	}
@@ -37,9 +40,12 @@ func (m *Application) resolveProfilesForExecutable(focusedPath string) rules.Pro
 
 func (m *Application) applyInAppFocusOverrides(base rules.Profiles) rules.Profiles {
 	m.inAppFocusProfilesLock.RLock()
-	kb := m.inAppFocusProfileKeyboard
-	ms := m.inAppFocusProfileMouse
+	overrides := FillDeskProfiles(rules.Profiles{
+		Keyboard: m.inAppFocusProfileKeyboard,
+		Mouse:    m.inAppFocusProfileMouse,
+	})
 	m.inAppFocusProfilesLock.RUnlock()
+	kb, ms := overrides.Keyboard, overrides.Mouse
 
 	if kb == nil && ms == nil {
 		return base
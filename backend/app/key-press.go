@@ -0,0 +1,120 @@
+package app
+
+import (
+	"time"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/key"
+)
+
+// keyPress is the recorded press of a key that is currently down.
+type keyPress struct {
+	// The time the key was pressed.
+	At time.Time
+	// The time since the previous key press, or zero if there was no previous key press.
+	Interval time.Duration
+}
+
+// holdTimers are the pending hold sounds of a key press. A key press can have several hold sounds when
+// profiles are blended.
+type holdTimers struct {
+	// The time the key was pressed.
+	PressedAt time.Time
+	// The timers of the hold sounds.
+	Timers []*time.Timer
+}
+
+// recordKeyPress records the press of a key and returns it.
+func (m *Application) recordKeyPress(k key.Key) keyPress {
+	press := keyPress{At: time.Now()}
+
+	m.keyboardPressStateLock.Lock()
+	defer m.keyboardPressStateLock.Unlock()
+
+	if !m.keyboardLastPressAt.IsZero() {
+		press.Interval = press.At.Sub(m.keyboardLastPressAt)
+	}
+	m.keyboardLastPressAt = press.At
+
+	if m.keyboardKeysPressed == nil {
+		m.keyboardKeysPressed = make(map[uint32]keyPress)
+	}
+	m.keyboardKeysPressed[k.Code] = press
+
+	return press
+}
+
+// recordKeyRelease clears the press state of a key, cancelling its pending hold sound, and returns the press of
+// the key and how long the key was held down. If the press of the key was not recorded, it returns zero values.
+func (m *Application) recordKeyRelease(k key.Key) (keyPress, time.Duration) {
+	m.keyboardPressStateLock.Lock()
+	defer m.keyboardPressStateLock.Unlock()
+
+	if pending, ok := m.keyboardHoldTimers[k.Code]; ok {
+		pending.stop()
+		delete(m.keyboardHoldTimers, k.Code)
+	}
+
+	press, ok := m.keyboardKeysPressed[k.Code]
+	if !ok {
+		return keyPress{}, 0
+	}
+	delete(m.keyboardKeysPressed, k.Code)
+
+	return press, time.Since(press.At)
+}
+
+// scheduleHoldSound calls play once a key has been held down for the given duration since pressedAt. If the
+// key is released, or released and pressed again, before then, play is not called.
+func (m *Application) scheduleHoldSound(k key.Key, pressedAt time.Time, after time.Duration, play func()) {
+	m.keyboardPressStateLock.Lock()
+	defer m.keyboardPressStateLock.Unlock()
+
+	// The key may have been released before the press was processed.
+	if current, ok := m.keyboardKeysPressed[k.Code]; !ok || !current.At.Equal(pressedAt) {
+		return
+	}
+
+	if m.keyboardHoldTimers == nil {
+		m.keyboardHoldTimers = make(map[uint32]holdTimers)
+	}
+
+	// Hold sounds of an earlier press of the key are replaced, while hold sounds of the same press are kept.
+	pending := m.keyboardHoldTimers[k.Code]
+	if !pending.PressedAt.Equal(pressedAt) {
+		pending.stop()
+		pending = holdTimers{PressedAt: pressedAt}
+	}
+
+	pending.Timers = append(pending.Timers, time.AfterFunc(time.Until(pressedAt.Add(after)), func() {
+		m.keyboardPressStateLock.Lock()
+		current, ok := m.keyboardKeysPressed[k.Code]
+		stillHeld := ok && current.At.Equal(pressedAt)
+		m.keyboardPressStateLock.Unlock()
+
+		if stillHeld {
+			play()
+		}
+	}))
+	m.keyboardHoldTimers[k.Code] = pending
+}
+
+// stop cancels the pending hold sounds.
+func (h holdTimers) stop() {
+	for _, timer := range h.Timers {
+		timer.Stop()
+	}
+}
+
+// resetKeyPressState clears the press state of all keys and cancels all pending hold sounds.
+func (m *Application) resetKeyPressState() {
+	m.keyboardPressStateLock.Lock()
+	defer m.keyboardPressStateLock.Unlock()
+
+	for _, pending := range m.keyboardHoldTimers {
+		pending.stop()
+	}
+
+	m.keyboardHoldTimers = nil
+	m.keyboardKeysPressed = nil
+	m.keyboardLastPressAt = time.Time{}
+}
@@ -0,0 +1,87 @@
+package app
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// KeyRepeatMode represents how sounds are played for key repeat events, which the operating system generates
+// while a key is held down.
+type KeyRepeatMode string
+
+const (
+	// KeyRepeatModeOff represents playing no sound for key repeat events.
+	KeyRepeatModeOff KeyRepeatMode = "off"
+	// KeyRepeatModeAll represents playing a sound for every key repeat event.
+	KeyRepeatModeAll KeyRepeatMode = "all"
+	// KeyRepeatModeThrottled represents playing sounds for key repeat events up to a maximum rate.
+	KeyRepeatModeThrottled KeyRepeatMode = "throttled"
+)
+
+// DefaultKeyRepeatMaxRate is the default maximum number of key repeat sounds played per second when the key
+// repeat mode is KeyRepeatModeThrottled.
+const DefaultKeyRepeatMaxRate = 15.0
+
+type appKeyRepeatConfig struct {
+	Mode    KeyRepeatMode
+	MaxRate float64
+	// The time the last key repeat sound was played, used for throttling.
+	LastPlayed time.Time
+	Lock       sync.Mutex
+}
+
+// SetKeyRepeat sets how sounds are played for key repeat events. The maximum rate is the number of key repeat
+// sounds played per second when the mode is KeyRepeatModeThrottled. Unknown modes are treated as
+// KeyRepeatModeOff, and a maximum rate that is not positive is replaced with DefaultKeyRepeatMaxRate.
+func (m *Application) SetKeyRepeat(mode KeyRepeatMode, maxRate float64) {
+	switch mode {
+	case KeyRepeatModeOff, KeyRepeatModeAll, KeyRepeatModeThrottled:
+	default:
+		mode = KeyRepeatModeOff
+	}
+
+	if maxRate <= 0 {
+		maxRate = DefaultKeyRepeatMaxRate
+	}
+
+	m.keyRepeatConfig.Lock.Lock()
+	defer m.keyRepeatConfig.Lock.Unlock()
+
+	m.keyRepeatConfig.Mode = mode
+	m.keyRepeatConfig.MaxRate = maxRate
+	m.keyRepeatConfig.LastPlayed = time.Time{}
+
+	slog.Info("Set key repeat", "mode", mode, "maxRate", maxRate)
+}
+
+// GetKeyRepeat returns how sounds are played for key repeat events.
+func (m *Application) GetKeyRepeat() (mode KeyRepeatMode, maxRate float64) {
+	m.keyRepeatConfig.Lock.Lock()
+	defer m.keyRepeatConfig.Lock.Unlock()
+
+	return m.keyRepeatConfig.Mode, m.keyRepeatConfig.MaxRate
+}
+
+// shouldPlayKeyRepeat checks if a sound should be played for a key repeat event according to the key repeat
+// mode. In KeyRepeatModeThrottled, a sound is played if enough time has passed since the last key repeat sound.
+func (m *Application) shouldPlayKeyRepeat() bool {
+	m.keyRepeatConfig.Lock.Lock()
+	defer m.keyRepeatConfig.Lock.Unlock()
+
+	switch m.keyRepeatConfig.Mode {
+	case KeyRepeatModeAll:
+		return true
+	case KeyRepeatModeThrottled:
+		now := time.Now()
+		interval := time.Duration(float64(time.Second) / m.keyRepeatConfig.MaxRate)
+		if now.Sub(m.keyRepeatConfig.LastPlayed) < interval {
+			return false
+		}
+
+		m.keyRepeatConfig.LastPlayed = now
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,214 @@
+package app
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"sync"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/audio"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+	"github.com/samber/lo"
+)
+
+// ProfileBlend is a weighted mix of profiles that play together for a device. For every event, a sound is
+// chosen from the active profile of the device, as selected by the application rules, and from each of the
+// blend layers, and the sounds are played together.
+type ProfileBlend struct {
+	// The weight of the active profile, as a linear volume multiplier between 0 and 1.
+	Weight float64 `json:"weight"`
+	// The profiles mixed with the active profile.
+	Layers []BlendLayer `json:"layers"`
+}
+
+// BlendLayer is a profile mixed with the active profile of a device.
+type BlendLayer struct {
+	// The ID or name of the profile.
+	Profile string `json:"profile"`
+	// The weight of the profile, as a linear volume multiplier between 0 and 1.
+	Weight float64 `json:"weight"`
+}
+
+// DefaultProfileBlend returns a blend that plays only the active profile of a device, at full volume.
+func DefaultProfileBlend() ProfileBlend {
+	return ProfileBlend{Weight: 1, Layers: []BlendLayer{}}
+}
+
+// loadedProfile is a profile whose sources and audio files are loaded into memory.
+type loadedProfile struct {
+	Profile *profile.Profile
+	// The sources of the profile, by ID.
+	Sources map[string]profile.SourceConfig
+	// The audio files of the sources, by full path.
+	AudioCache map[string]*audio.Audio
+}
+
+// blendLayer is a loaded blend layer.
+type blendLayer struct {
+	loadedProfile
+	Weight float64
+}
+
+type appProfileBlendConfig struct {
+	// The weight of the active profile.
+	Weight float64
+	Layers []blendLayer
+	// The number of times the blend was set, used to discard reloads that started before the blend was changed.
+	Generation uint64
+	Lock       sync.RWMutex
+}
+
+// SetKeyboardBlend sets the profiles that are mixed with the active keyboard profile. The audio files of the
+// blend layers are loaded into memory before the blend is applied.
+func (m *Application) SetKeyboardBlend(blend ProfileBlend) error {
+	return m.keyboardBlendConfig.set(blend, (*profile.Profile).SupportsKeyboard, "keyboard")
+}
+
+// GetKeyboardBlend returns the profiles that are mixed with the active keyboard profile.
+func (m *Application) GetKeyboardBlend() ProfileBlend {
+	return m.keyboardBlendConfig.get()
+}
+
+// SetMouseBlend sets the profiles that are mixed with the active mouse profile. The audio files of the blend
+// layers are loaded into memory before the blend is applied.
+func (m *Application) SetMouseBlend(blend ProfileBlend) error {
+	return m.mouseBlendConfig.set(blend, (*profile.Profile).SupportsMouse, "mouse")
+}
+
+// GetMouseBlend returns the profiles that are mixed with the active mouse profile.
+func (m *Application) GetMouseBlend() ProfileBlend {
+	return m.mouseBlendConfig.get()
+}
+
+// set validates a blend, loads the profiles of its layers and applies it.
+func (c *appProfileBlendConfig) set(blend ProfileBlend, supports func(*profile.Profile) bool, device string) error {
+	if err := validateBlendWeight(blend.Weight); err != nil {
+		return fmt.Errorf("invalid weight for active %s profile: %w", device, err)
+	}
+
+	layers := make([]blendLayer, 0, len(blend.Layers))
+	for _, layer := range blend.Layers {
+		if err := validateBlendWeight(layer.Weight); err != nil {
+			return fmt.Errorf("invalid weight for profile %s: %w", layer.Profile, err)
+		}
+
+		p, ok := profile.FindProfile(layer.Profile)
+		if !ok {
+			return fmt.Errorf("%w: %s", profile.ErrProfileNotFound, layer.Profile)
+		}
+
+		if !supports(p) {
+			return fmt.Errorf("profile is not a %s profile: %s", device, p.Details.Name)
+		}
+
+		loaded, err := loadProfile(p)
+		if err != nil {
+			return fmt.Errorf("failed to load profile %s: %w", p.Details.Name, err)
+		}
+
+		layers = append(layers, blendLayer{loadedProfile: loaded, Weight: layer.Weight})
+	}
+
+	c.Lock.Lock()
+	c.Weight = blend.Weight
+	c.Layers = layers
+	c.Generation++
+	c.Lock.Unlock()
+
+	slog.Info("Set profile blend", "device", device, "weight", blend.Weight, "layers", len(layers))
+
+	return nil
+}
+
+// get returns the blend, with the layers referring to their profiles by ID.
+func (c *appProfileBlendConfig) get() ProfileBlend {
+	c.Lock.RLock()
+	defer c.Lock.RUnlock()
+
+	return ProfileBlend{
+		Weight: c.Weight,
+		Layers: lo.Map(c.Layers, func(layer blendLayer, _ int) BlendLayer {
+			return BlendLayer{Profile: layer.Profile.ID, Weight: layer.Weight}
+		}),
+	}
+}
+
+// snapshot returns the weight of the active profile and the blend layers.
+func (c *appProfileBlendConfig) snapshot() (float64, []blendLayer) {
+	c.Lock.RLock()
+	defer c.Lock.RUnlock()
+
+	return c.Weight, c.Layers
+}
+
+// reloadChanged reloads the blend layers whose profiles are located in one of the changed locations. Layers
+// whose profiles are no longer available are removed. The audio files are loaded without holding the lock, so
+// the reloaded layers are discarded if the blend is set while they are loaded.
+func (c *appProfileBlendConfig) reloadChanged(changed []string) {
+	c.Lock.RLock()
+	layers, generation := c.Layers, c.Generation
+	c.Lock.RUnlock()
+	if !lo.SomeBy(layers, func(layer blendLayer) bool { return isProfileChanged(layer.Profile, changed) }) {
+		return
+	}
+
+	reloaded := make([]blendLayer, 0, len(layers))
+	for _, layer := range layers {
+		if !isProfileChanged(layer.Profile, changed) {
+			reloaded = append(reloaded, layer)
+			continue
+		}
+
+		p, ok := profile.FindProfile(layer.Profile.ID)
+		if !ok {
+			slog.Warn("Blended profile is no longer available", "profile", layer.Profile.ID)
+			continue
+		}
+
+		loaded, err := loadProfile(p)
+		if err != nil {
+			slog.Error("failed to reload blended profile", "profile", p.Details.Name, "error", err)
+			continue
+		}
+
+		slog.Info("Reloading blended", "profile", p.Details.Name)
+		reloaded = append(reloaded, blendLayer{loadedProfile: loaded, Weight: layer.Weight})
+	}
+
+	c.Lock.Lock()
+	defer c.Lock.Unlock()
+
+	if c.Generation != generation {
+		slog.Debug("Discarding reloaded blend, the blend was set while it was reloaded")
+		return
+	}
+	c.Layers = reloaded
+}
+
+// loadProfile loads the sources and audio files of a profile into memory.
+func loadProfile(p *profile.Profile) (loadedProfile, error) {
+	sources, audioCache, err := loadProfileAudio(p)
+	if err != nil {
+		return loadedProfile{}, err
+	}
+
+	return loadedProfile{Profile: p, Sources: sources, AudioCache: audioCache}, nil
+}
+
+// validateBlendWeight checks that a blend weight is between 0 and 1.
+func validateBlendWeight(weight float64) error {
+	if math.IsNaN(weight) || weight < 0 || weight > 1 {
+		return fmt.Errorf("weight %v must be between 0 and 1", weight)
+	}
+
+	return nil
+}
+
+// weightAdjustments returns the adjustments of a source with its gain scaled by a blend weight.
+func weightAdjustments(adjustments profile.SourceAdjustments, weight float64) profile.SourceAdjustments {
+	if weight != 1 {
+		adjustments.GainDB += 20 * math.Log10(weight)
+	}
+
+	return adjustments
+}
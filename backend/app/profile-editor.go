@@ -0,0 +1,18 @@
+package app
+
+import (
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+)
+
+// SaveProfileEdits saves the edits made to a profile and reloads the active keyboard and mouse profiles and
+// blend layers that use it, so that the edits apply immediately.
+func (m *Application) SaveProfileEdits(editor *profile.Editor) (*profile.Profile, error) {
+	saved, err := editor.Save()
+	if err != nil {
+		return nil, err
+	}
+
+	m.reloadChangedProfiles([]string{saved.Location})
+
+	return saved, nil
+}
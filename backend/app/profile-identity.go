@@ -0,0 +1,65 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/rules"
+)
+
+// RenameProfile renames a profile. Rules and active profiles are tracked by profile ID, so they keep
+// pointing at the renamed profile; any that still refer to it by its old name are rewritten to its ID.
+func (m *Application) RenameProfile(ref string, newName string) (*profile.Profile, error) {
+	existing, ok := profile.FindProfile(ref)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", profile.ErrProfileNotFound, ref)
+	}
+	oldName := existing.Details.Name
+
+	renamed, err := profile.RenameProfile(ref, newName)
+	if err != nil {
+		return nil, err
+	}
+
+	rewrite := func(r string) string {
+		if strings.EqualFold(r, oldName) {
+			return renamed.ID
+		}
+		return r
+	}
+
+	err = rules.RewriteProfileReferences(rewrite)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update rules for renamed profile: %w", err)
+	}
+
+	m.currentProfilesLock.Lock()
+	m.currentProfiles.RewriteReferences(rewrite)
+	m.currentProfilesLock.Unlock()
+
+	m.inAppFocusProfilesLock.Lock()
+	focusProfiles := rules.Profiles{Keyboard: m.inAppFocusProfileKeyboard, Mouse: m.inAppFocusProfileMouse}
+	focusProfiles.RewriteReferences(rewrite)
+	m.inAppFocusProfileKeyboard = focusProfiles.Keyboard
+	m.inAppFocusProfileMouse = focusProfiles.Mouse
+	m.inAppFocusProfilesLock.Unlock()
+
+	m.reloadChangedProfiles([]string{renamed.Location})
+
+	return renamed, nil
+}
+
+// DuplicateProfile copies a profile to a new profile with the given name.
+func (m *Application) DuplicateProfile(ref string, newName string) (*profile.Profile, error) {
+	duplicate, err := profile.DuplicateProfile(ref, newName)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, delegate := range profilesChangedDelegates {
+		go delegate()
+	}
+
+	return duplicate, nil
+}
@@ -0,0 +1,233 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/rules"
+	"github.com/samber/lo"
+)
+
+// RotationMode represents when the rotated profiles are switched.
+type RotationMode string
+
+const (
+	// RotationModeOff represents not rotating profiles. The default profiles are used.
+	RotationModeOff RotationMode = "off"
+	// RotationModeManual represents rotating profiles only when RotateProfiles is called, such as from a hot key.
+	RotationModeManual RotationMode = "manual"
+	// RotationModeSession represents picking profiles once when the rotation is configured, which is at startup.
+	RotationModeSession RotationMode = "session"
+	// RotationModeTimer represents picking new profiles every RotationConfig.IntervalMinutes.
+	RotationModeTimer RotationMode = "timer"
+)
+
+// DefaultRotationIntervalMinutes is the default number of minutes between rotations in RotationModeTimer.
+const DefaultRotationIntervalMinutes = 60
+
+// RotationPool is the set of profiles that a device rotates through. A device with an empty pool is not
+// rotated.
+type RotationPool struct {
+	// The IDs or names of the profiles in the pool.
+	Profiles []string `json:"profiles"`
+	// Profiles with any of these tags are in the pool as well.
+	Tags []string `json:"tags"`
+}
+
+// IsEmpty returns true if the pool has no profiles and no tags.
+func (p RotationPool) IsEmpty() bool {
+	return len(p.Profiles) == 0 && len(p.Tags) == 0
+}
+
+// RotationConfig configures the rotation of the default keyboard and mouse profiles. The rotated profiles
+// replace the default profiles, so applications with their own rule keep their profiles.
+type RotationConfig struct {
+	Mode RotationMode `json:"mode"`
+	// The number of minutes between rotations in RotationModeTimer.
+	IntervalMinutes int          `json:"intervalMinutes"`
+	Keyboard        RotationPool `json:"keyboard"`
+	Mouse           RotationPool `json:"mouse"`
+}
+
+type appProfileRotationConfig struct {
+	Config RotationConfig
+	// The IDs of the profiles picked by the last rotation. nil if the device has not been rotated.
+	Keyboard *string
+	Mouse    *string
+	// Stops the rotation timer, if it is running.
+	stopTimer context.CancelFunc
+	Lock      sync.Mutex
+}
+
+// SetProfileRotation configures the rotation of the default profiles. In RotationModeSession and
+// RotationModeTimer, new profiles are picked immediately. In RotationModeOff, the default profiles are
+// restored.
+func (m *Application) SetProfileRotation(config RotationConfig) error {
+	switch config.Mode {
+	case RotationModeOff, RotationModeManual, RotationModeSession, RotationModeTimer:
+	default:
+		return fmt.Errorf("unknown rotation mode: %s", config.Mode)
+	}
+
+	if config.IntervalMinutes <= 0 {
+		config.IntervalMinutes = DefaultRotationIntervalMinutes
+	}
+
+	m.profileRotationConfig.Lock.Lock()
+	if m.profileRotationConfig.stopTimer != nil {
+		m.profileRotationConfig.stopTimer()
+		m.profileRotationConfig.stopTimer = nil
+	}
+
+	m.profileRotationConfig.Config = config
+	if config.Mode == RotationModeOff {
+		m.profileRotationConfig.Keyboard = nil
+		m.profileRotationConfig.Mouse = nil
+	}
+
+	if config.Mode == RotationModeTimer {
+		ctx, cancel := context.WithCancel(context.Background())
+		m.profileRotationConfig.stopTimer = cancel
+		go m.profileRotationTimer(ctx, time.Duration(config.IntervalMinutes)*time.Minute)
+	}
+	m.profileRotationConfig.Lock.Unlock()
+
+	slog.Info("Set profile rotation", "mode", config.Mode, "intervalMinutes", config.IntervalMinutes)
+
+	if config.Mode == RotationModeSession || config.Mode == RotationModeTimer {
+		return m.RotateProfiles(true, true)
+	}
+
+	m.reapplyDefaultProfiles()
+	return nil
+}
+
+// GetProfileRotation returns the configuration of the rotation of the default profiles.
+func (m *Application) GetProfileRotation() RotationConfig {
+	m.profileRotationConfig.Lock.Lock()
+	defer m.profileRotationConfig.Lock.Unlock()
+
+	return m.profileRotationConfig.Config
+}
+
+// GetRotatedProfiles returns the IDs of the profiles picked by the last rotation. A device is nil if it has
+// not been rotated.
+func (m *Application) GetRotatedProfiles() rules.Profiles {
+	m.profileRotationConfig.Lock.Lock()
+	defer m.profileRotationConfig.Lock.Unlock()
+
+	return rules.Profiles{
+		Keyboard: cloneStrPtr(m.profileRotationConfig.Keyboard),
+		Mouse:    cloneStrPtr(m.profileRotationConfig.Mouse),
+	}
+}
+
+// RotateProfiles picks new profiles from the rotation pools of the given devices and applies them. A device
+// with an empty pool is not rotated. When a pool has more than one profile, the current profile is not picked
+// again. RotateProfiles does nothing in RotationModeOff.
+func (m *Application) RotateProfiles(keyboard bool, mouse bool) error {
+	m.profileRotationConfig.Lock.Lock()
+	config := m.profileRotationConfig.Config
+	if config.Mode == RotationModeOff || config.Mode == "" {
+		m.profileRotationConfig.Lock.Unlock()
+		return fmt.Errorf("profile rotation is off")
+	}
+
+	if keyboard {
+		m.profileRotationConfig.Keyboard = pickRotationProfile(
+			config.Keyboard, profile.GetKeyboardProfiles(), m.profileRotationConfig.Keyboard)
+	}
+	if mouse {
+		m.profileRotationConfig.Mouse = pickRotationProfile(
+			config.Mouse, profile.GetMouseProfiles(), m.profileRotationConfig.Mouse)
+	}
+
+	slog.Info("Rotated profiles", "keyboard", m.profileRotationConfig.Keyboard, "mouse", m.profileRotationConfig.Mouse)
+	m.profileRotationConfig.Lock.Unlock()
+
+	m.reapplyDefaultProfiles()
+	return nil
+}
+
+// profileRotationTimer rotates the profiles of both devices every interval until ctx is cancelled.
+func (m *Application) profileRotationTimer(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			err := m.RotateProfiles(true, true)
+			if err != nil {
+				slog.Error("failed to rotate profiles", "error", err)
+			}
+		}
+	}
+}
+
+// pickRotationProfile picks a random profile from a rotation pool, returning its ID. Only the given
+// candidates, which are the profiles that can be used for the device, are picked. The current profile is
+// only picked again if it is the only profile in the pool. It returns nil if the pool is empty.
+func pickRotationProfile(pool RotationPool, candidates []*profile.Profile, current *string) *string {
+	if pool.IsEmpty() {
+		return nil
+	}
+
+	inPool := lo.Filter(candidates, func(p *profile.Profile, _ int) bool {
+		inProfiles := lo.ContainsBy(pool.Profiles, func(ref string) bool {
+			return profile.ResolveProfileID(ref) == p.ID
+		})
+		return inProfiles || lo.SomeBy(pool.Tags, p.HasTag)
+	})
+
+	if len(inPool) == 0 {
+		slog.Warn("No profiles available in rotation pool", "profiles", pool.Profiles, "tags", pool.Tags)
+		return nil
+	}
+
+	if current != nil && len(inPool) > 1 {
+		inPool = lo.Filter(inPool, func(p *profile.Profile, _ int) bool {
+			return p.ID != *current
+		})
+	}
+
+	picked := inPool[rand.Intn(len(inPool))].ID
+	return &picked
+}
+
+// applyRotationOverrides returns the default profiles with the profiles picked by the last rotation in place
+// of the default profiles of the rotated devices.
+func (m *Application) applyRotationOverrides(base rules.Profiles) rules.Profiles {
+	rotated := FillDeskProfiles(m.GetRotatedProfiles())
+	if rotated.Keyboard != nil {
+		base.Keyboard = rotated.Keyboard
+	}
+	if rotated.Mouse != nil {
+		base.Mouse = rotated.Mouse
+	}
+
+	return base
+}
+
+// reapplyDefaultProfiles applies the default profiles again, so that changes to the rotated profiles apply
+// immediately. On Linux, which has no application rules, the default profiles are always active.
+func (m *Application) reapplyDefaultProfiles() {
+	if runtime.GOOS != "linux" {
+		m.reevaluateProfilesForCurrentFocus()
+		return
+	}
+
+	m.currentProfilesLock.RLock()
+	defaults := m.linuxDefaultProfiles
+	m.currentProfilesLock.RUnlock()
+
+	m.updateProfiles(m.applyRotationOverrides(defaults))
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+	"github.com/samber/lo"
+)
+
+// profileWatchInterval is how often the profiles directory is polled for changes.
+const profileWatchInterval = 2 * time.Second
+
+// ProfilesChangedDelegate is called after profiles have been reloaded because they changed on disk
+type ProfilesChangedDelegate func()
+
+var profilesChangedDelegates = []ProfilesChangedDelegate{}
+
+// RegisterProfilesChangedDelegate registers a delegate for profile changes on disk
+func RegisterProfilesChangedDelegate(delegate ProfilesChangedDelegate) {
+	profilesChangedDelegates = append(profilesChangedDelegates, delegate)
+}
+
+// watchProfiles watches the profiles directory and reloads the active profiles when their files change.
+func (m *Application) watchProfiles(ctx context.Context) {
+	profile.WatchProfiles(ctx, profileWatchInterval, m.reloadChangedProfiles)
+}
+
+// reloadChangedProfiles reloads the audio for the active keyboard and mouse profiles, and the profiles
+// blended with them, if any of the given profile locations belong to them, and notifies all delegates that
+// profiles have changed.
+func (m *Application) reloadChangedProfiles(changed []string) {
+	m.currentProfilesLock.RLock()
+	current := m.currentProfiles
+	m.currentProfilesLock.RUnlock()
+
+	m.keyboardProfileLock.RLock()
+	keyboardChanged := isProfileChanged(m.keyboardProfile, changed)
+	m.keyboardProfileLock.RUnlock()
+
+	if keyboardChanged && current.Keyboard != nil {
+		p, ok := profile.FindProfile(*current.Keyboard)
+		if !ok {
+			slog.Warn("Active keyboard profile is no longer available", "profile", *current.Keyboard)
+		} else {
+			slog.Info("Reloading keyboard", "profile", p.Details.Name)
+			err := m.setKeyboardProfile(p)
+			if err != nil {
+				slog.Error("failed to reload keyboard profile", "error", err)
+			}
+		}
+	}
+
+	m.mouseProfileLock.RLock()
+	mouseChanged := isProfileChanged(m.mouseProfile, changed)
+	m.mouseProfileLock.RUnlock()
+
+	if mouseChanged && current.Mouse != nil {
+		p, ok := profile.FindProfile(*current.Mouse)
+		if !ok {
+			slog.Warn("Active mouse profile is no longer available", "profile", *current.Mouse)
+		} else {
+			slog.Info("Reloading mouse", "profile", p.Details.Name)
+			err := m.setMouseProfile(p)
+			if err != nil {
+				slog.Error("failed to reload mouse profile", "error", err)
+			}
+		}
+	}
+
+	m.keyboardBlendConfig.reloadChanged(changed)
+	m.mouseBlendConfig.reloadChanged(changed)
+
+	for _, delegate := range profilesChangedDelegates {
+		go delegate()
+	}
+}
+
+// isProfileChanged returns true if the profile, a profile it extends, or a profile it inherits sources from, is
+// located in one of the changed locations.
+func isProfileChanged(p *profile.Profile, changed []string) bool {
+	if p == nil {
+		return false
+	}
+
+	if lo.Contains(changed, p.Location) || lo.Some(changed, p.ParentLocations) {
+		return true
+	}
+
+	return lo.ContainsBy(p.Sources, func(s profile.Source) bool {
+		return lo.Contains(changed, s.Location)
+	})
+}
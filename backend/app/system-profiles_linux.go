@@ -0,0 +1,34 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+)
+
+// systemProfileLayers returns the read-only system-wide profiles directories, in order of precedence. Profiles
+// installed for every user, such as team-standard packs, are looked up in keyboardsounds-pro/profiles under
+// each of the XDG data directories, for example /usr/share/keyboardsounds-pro/profiles.
+func systemProfileLayers() []profile.Layer {
+	dataDirs := os.Getenv("XDG_DATA_DIRS")
+	if dataDirs == "" {
+		dataDirs = "/usr/local/share:/usr/share"
+	}
+
+	layers := make([]profile.Layer, 0)
+	for _, dir := range strings.Split(dataDirs, ":") {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+
+		layers = append(layers, profile.Layer{
+			Name:     "system",
+			Dir:      filepath.Join(dir, "keyboardsounds-pro", "profiles"),
+			ReadOnly: true,
+		})
+	}
+
+	return layers
+}
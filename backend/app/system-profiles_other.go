@@ -0,0 +1,11 @@
+//go:build !linux
+
+package app
+
+import "github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+
+// systemProfileLayers returns the read-only system-wide profiles directories. System-wide profiles are only
+// supported on Linux.
+func systemProfileLayers() []profile.Layer {
+	return nil
+}
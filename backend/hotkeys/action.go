@@ -28,6 +28,7 @@ const (
 	HotKeyActionIncreaseVolume   HotKeyAction = "increase-volume"
 	HotKeyActionDecreaseVolume   HotKeyAction = "decrease-volume"
 	HotKeyActionToggleOSKHelpers HotKeyAction = "toggle-osk-helpers"
+	HotKeyActionRotateProfiles   HotKeyAction = "rotate-profiles"
 )
 
 // HotKeyDeviceAction represents the action for a hot key on a specific device.
@@ -30,6 +30,7 @@ func main() {
 			}
 			fmt.Printf("      Press: %v\n", sourceConfig.Press)
 			fmt.Printf("      Release: %v\n", sourceConfig.Release)
+			fmt.Printf("      Repeat: %v\n", sourceConfig.Repeat)
 		}
 	}
 }
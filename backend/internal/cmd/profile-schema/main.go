@@ -0,0 +1,195 @@
+// Command profile-schema generates the JSON Schema of profile.yaml files from the types of the profile package.
+// Field descriptions are taken from the doc comments of the profile package sources.
+//
+//	go run ./internal/cmd/profile-schema -src ./profile -o ../docs/profile.schema.json
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+)
+
+// schemaID is the URL that the schema is published at.
+const schemaID = "https://keyboardsounds.pro/docs/profile.schema.json"
+
+// schema is a JSON Schema object.
+type schema map[string]any
+
+func main() {
+	src := flag.String("src", ".", "directory of the profile package sources, for field descriptions")
+	out := flag.String("o", "", "file to write the schema to, or stdout if empty")
+	flag.Parse()
+
+	docs, err := fieldDocs(*src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read field descriptions: %v\n", err)
+		os.Exit(1)
+	}
+
+	root := generator{docs: docs}.object(reflect.TypeOf(profile.Profile{}))
+	root["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	root["$id"] = schemaID
+	root["title"] = "Keyboard Sounds Pro profile"
+	root["description"] = "The profile.yaml file of a Keyboard Sounds Pro sound profile."
+
+	data, err := json.MarshalIndent(root, "", "  ")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal schema: %v\n", err)
+		os.Exit(1)
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		os.Stdout.Write(data)
+		return
+	}
+
+	if err := os.WriteFile(*out, data, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write schema: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// generator builds the schema of the profile types.
+type generator struct {
+	// The doc comments of struct fields, by "Type.Field".
+	docs map[string]string
+}
+
+// required lists the fields that must be set, by type.
+var required = map[reflect.Type][]string{
+	reflect.TypeOf(profile.ProfileDetails{}): {"name"},
+	reflect.TypeOf(profile.Source{}):         {"id", "source"},
+	reflect.TypeOf(profile.Key{}):            {"sound"},
+	reflect.TypeOf(profile.Button{}):         {"sound"},
+	reflect.TypeOf(profile.SourceLayer{}):    {"max_interval_ms", "source"},
+}
+
+// typeOf returns the schema of a type.
+func (g generator) typeOf(t reflect.Type) schema {
+	switch t {
+	case reflect.TypeOf(profile.SoundRef{}):
+		return schema{"oneOf": []schema{
+			{"type": "string", "description": "A source ID."},
+			{"type": "array", "items": schema{"type": "string"}, "description": "Source IDs, one of which is picked at random each time a sound is played."},
+		}}
+	case reflect.TypeOf(profile.SourceFiles{}):
+		return schema{"oneOf": []schema{
+			{"type": "string", "description": "The audio file played when the key is pressed."},
+			g.object(t),
+		}}
+	case reflect.TypeOf(profile.PitchRange{}):
+		return schema{"oneOf": []schema{
+			{"type": "number", "description": "A fixed pitch shift."},
+			{"type": "array", "items": schema{"type": "number"}, "minItems": 2, "maxItems": 2, "description": "A [min, max] range of pitch shifts."},
+		}}
+	case reflect.TypeOf(profile.DeviceType("")):
+		return schema{"enum": []profile.DeviceType{profile.DeviceTypeKeyboard, profile.DeviceTypeMouse, profile.DeviceTypeDesk}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.typeOf(t.Elem())
+	case reflect.Struct:
+		return g.object(t)
+	case reflect.Slice:
+		return schema{"type": "array", "items": g.typeOf(t.Elem())}
+	case reflect.Map:
+		return schema{"type": "object", "additionalProperties": g.typeOf(t.Elem())}
+	case reflect.String:
+		return schema{"type": "string"}
+	case reflect.Bool:
+		return schema{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return schema{"type": "integer"}
+	case reflect.Float64:
+		return schema{"type": "number"}
+	default:
+		panic(fmt.Sprintf("no schema for type %v", t))
+	}
+}
+
+// object returns the schema of a struct type from the YAML names of its fields.
+func (g generator) object(t reflect.Type) schema {
+	properties := make(schema)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		var property schema
+		switch {
+		case t == reflect.TypeOf(profile.Profile{}) && field.Name == "SchemaVersion":
+			property = schema{"type": "integer", "minimum": 0, "maximum": profile.CurrentSchemaVersion}
+		default:
+			property = g.typeOf(field.Type)
+		}
+
+		if doc, ok := g.docs[t.Name()+"."+field.Name]; ok {
+			property["description"] = doc
+		}
+		properties[name] = property
+	}
+
+	object := schema{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if fields, ok := required[t]; ok {
+		object["required"] = fields
+	}
+
+	return object
+}
+
+// fieldDocs returns the doc comments of the struct fields declared in the Go files of a directory, by
+// "Type.Field".
+func fieldDocs(dir string) (map[string]string, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make(map[string]string)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			ast.Inspect(file, func(node ast.Node) bool {
+				spec, ok := node.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+
+				structType, ok := spec.Type.(*ast.StructType)
+				if !ok {
+					return false
+				}
+
+				for _, field := range structType.Fields.List {
+					if field.Doc == nil {
+						continue
+					}
+					for _, name := range field.Names {
+						docs[spec.Name.Name+"."+name.Name] = strings.Join(strings.Fields(field.Doc.Text()), " ")
+					}
+				}
+				return false
+			})
+		}
+	}
+
+	return docs, nil
+}
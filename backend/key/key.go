@@ -310,6 +310,35 @@ func IsModifierKey(key Key) bool {
 	return lo.Contains(lo.Map(modifierKeys, func(k Key, _ int) uint32 { return k.Code }), key.Code)
 }
 
+// modifierAliases are names that match either the left or the right variant of a modifier key.
+var modifierAliases = map[string][]Key{
+	"shift":   {LeftShift, RightShift},
+	"ctrl":    {LeftControl, RightControl},
+	"control": {LeftControl, RightControl},
+	"alt":     {LeftAlt, RightAlt},
+	"option":  {LeftAlt, RightAlt},
+	"win":     {LeftWin, RightWin},
+	"cmd":     {LeftWin, RightWin},
+	"command": {LeftWin, RightWin},
+	"super":   {LeftWin, RightWin},
+	"meta":    {LeftWin, RightWin},
+}
+
+// FindModifier returns the keys matched by a modifier name and a bool indicating if the name is known. The name
+// can be an alias such as "shift" or "ctrl", which matches both the left and right variant of the modifier, or
+// the name of a specific key such as "LeftShift".
+func FindModifier(name string) ([]Key, bool) {
+	if keys, ok := modifierAliases[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return keys, true
+	}
+
+	if k, ok := FindKey(strings.TrimSpace(name)); ok {
+		return []Key{k}, true
+	}
+
+	return nil, false
+}
+
 // FindKeyCode returns a Key based on the platform specific code. If the key is
 // not found, it returns a Key with the provided code and no name.
 func FindKeyCode(val uint32) Key {
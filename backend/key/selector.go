@@ -0,0 +1,150 @@
+package key
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// SelectorPrefix is the prefix that distinguishes a selector from a key name.
+const SelectorPrefix = "@"
+
+// ErrInvalidSelector is returned when a selector cannot be parsed.
+var ErrInvalidSelector = errors.New("invalid key selector")
+
+// keyGroups are the named groups of keys that can be selected with "@<group>".
+var keyGroups = map[string][]Key{
+	"letters": {A, B, C, D, E, F, G, H, I, J, K, L, M, N, O, P, Q, R, S, T, U, V, W, X, Y, Z},
+	"digits": {
+		Number0, Number1, Number2, Number3, Number4, Number5, Number6, Number7, Number8, Number9,
+	},
+	"modifiers": {
+		LeftShift, RightShift, LeftControl, RightControl, LeftAlt, RightAlt, LeftWin, RightWin,
+	},
+	"numpad": {
+		NumPad0, NumPad1, NumPad2, NumPad3, NumPad4, NumPad5, NumPad6, NumPad7, NumPad8, NumPad9,
+		NumPadMultiply, NumPadAdd, NumPadSubtract, NumPadDecimal, NumPadDivide, NumLock,
+	},
+	"function": {
+		F1, F2, F3, F4, F5, F6, F7, F8, F9, F10, F11, F12,
+		F13, F14, F15, F16, F17, F18, F19, F20, F21, F22, F23, F24,
+	},
+	"arrows":     {Up, Down, Left, Right},
+	"navigation": {Insert, Delete, Home, End, PageUp, PageDown},
+	"punctuation": {
+		Backtick, Minus, Plus, LeftBracket, RightBracket, Backslash, SemiColon, Quote, Comma, Period, Slash,
+	},
+}
+
+// Selector selects a set of keys, either by a named group such as "@letters" or by a region of the keyboard
+// such as "@row:2" or "@column:1-5". Rows and columns refer to the positions returned by Key.GetPosition, so
+// region selectors never match keys without a position.
+type Selector struct {
+	// The selector as written, such as "@numpad".
+	Name string
+
+	keys    []Key
+	rows    *[2]int
+	columns *[2]int
+}
+
+// IsSelector returns true if a key name is a selector rather than the name of a key.
+func IsSelector(name string) bool {
+	return strings.HasPrefix(strings.TrimSpace(name), SelectorPrefix)
+}
+
+// ParseSelector parses a selector. Supported selectors are "@<group>" for the groups returned by ListGroups,
+// "@row:N" or "@row:N-M" for keyboard rows and "@column:N" or "@column:N-M" for keyboard columns.
+func ParseSelector(name string) (Selector, error) {
+	trimmed := strings.TrimSpace(name)
+	if !strings.HasPrefix(trimmed, SelectorPrefix) {
+		return Selector{}, fmt.Errorf("%w: %q does not start with %q", ErrInvalidSelector, name, SelectorPrefix)
+	}
+
+	body := strings.ToLower(strings.TrimPrefix(trimmed, SelectorPrefix))
+	selector := Selector{Name: trimmed}
+
+	kind, value, isRegion := strings.Cut(body, ":")
+	if !isRegion {
+		keys, ok := keyGroups[body]
+		if !ok {
+			return Selector{}, fmt.Errorf("%w: unknown group %q", ErrInvalidSelector, body)
+		}
+
+		selector.keys = keys
+		return selector, nil
+	}
+
+	bounds, err := parseRange(value)
+	if err != nil {
+		return Selector{}, fmt.Errorf("%w: %q: %w", ErrInvalidSelector, name, err)
+	}
+
+	switch kind {
+	case "row":
+		selector.rows = &bounds
+	case "column", "col":
+		selector.columns = &bounds
+	default:
+		return Selector{}, fmt.Errorf("%w: unknown region %q", ErrInvalidSelector, kind)
+	}
+
+	return selector, nil
+}
+
+// parseRange parses "N" or "N-M" into an inclusive range.
+func parseRange(value string) ([2]int, error) {
+	lowerStr, upperStr, isRange := strings.Cut(value, "-")
+	if !isRange {
+		upperStr = lowerStr
+	}
+
+	lower, err := strconv.Atoi(strings.TrimSpace(lowerStr))
+	if err != nil {
+		return [2]int{}, fmt.Errorf("invalid range %q", value)
+	}
+
+	upper, err := strconv.Atoi(strings.TrimSpace(upperStr))
+	if err != nil {
+		return [2]int{}, fmt.Errorf("invalid range %q", value)
+	}
+
+	if lower < 0 || upper < lower {
+		return [2]int{}, fmt.Errorf("invalid range %q", value)
+	}
+
+	return [2]int{lower, upper}, nil
+}
+
+// Matches returns true if the selector selects the given key.
+func (s Selector) Matches(k Key) bool {
+	if s.keys != nil {
+		return lo.ContainsBy(s.keys, k.Equals)
+	}
+
+	position := k.GetPosition()
+	if position == nil {
+		return false
+	}
+
+	if s.rows != nil {
+		return position.Y >= s.rows[0] && position.Y <= s.rows[1]
+	}
+
+	if s.columns != nil {
+		return position.X >= s.columns[0] && position.X <= s.columns[1]
+	}
+
+	return false
+}
+
+// ListGroups returns the names of the key groups, without the selector prefix, in alphabetical order.
+func ListGroups() []string {
+	groups := lo.Keys(keyGroups)
+	sort.Strings(groups)
+	return groups
+}
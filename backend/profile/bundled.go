@@ -0,0 +1,319 @@
+package profile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// BundledProfileChange describes a bundled profile that was installed, updated or kept by SyncBundledProfiles.
+type BundledProfileChange struct {
+	// The ID of the profile, which is the name of its directory.
+	ID string `json:"id"`
+	// The name of the profile.
+	Name string `json:"name"`
+	// The version of the installed profile before the sync, if any.
+	FromVersion string `json:"fromVersion"`
+	// The version of the bundled profile.
+	ToVersion string `json:"toVersion"`
+}
+
+// BundledSyncReport reports the changes made by SyncBundledProfiles.
+type BundledSyncReport struct {
+	// Bundled profiles that were not installed and have been installed.
+	Installed []BundledProfileChange `json:"installed"`
+	// Installed bundled profiles that had not been modified and have been replaced with a newer bundled version.
+	Updated []BundledProfileChange `json:"updated"`
+	// Installed bundled profiles that have a newer bundled version but were kept because they have been modified.
+	Kept []BundledProfileChange `json:"kept"`
+	// Bundled profiles that have a newer bundled version but were not installed because they have been deleted.
+	Skipped []BundledProfileChange `json:"skipped"`
+}
+
+// HasChanges returns true if any bundled profile was installed or updated, or has an update that was not applied.
+func (r BundledSyncReport) HasChanges() bool {
+	return len(r.Installed) > 0 || len(r.Updated) > 0 || len(r.Kept) > 0
+}
+
+// bundledState records the bundled profiles installed in a profiles directory.
+type bundledState struct {
+	// The installed bundled profiles, by ID.
+	Profiles map[string]bundledRecord `json:"profiles"`
+}
+
+// bundledRecord records an installed bundled profile.
+type bundledRecord struct {
+	// The version of the bundled profile when it was installed.
+	Version string `json:"version"`
+	// The content hash of the bundled profile when it was installed. A profile whose content hash no longer
+	// matches has been modified. The hash is empty when the profile was installed before bundled profiles were
+	// tracked and differed from the bundled version, so it is unknown whether it has been modified.
+	Hash string `json:"hash"`
+	// The content hash of the bundled version that the profile was last synced with, whether or not it was
+	// installed. Profiles that are kept or skipped are only reported when this changes.
+	BundledHash string `json:"bundledHash"`
+}
+
+// SyncBundledProfiles installs the bundled profiles in bundledDir into profilesDir. The installed bundled
+// profiles, and the content hash of each as it was installed, are recorded in the file at statePath.
+//
+// Bundled profiles that are not installed are installed, unless they were installed before and have since
+// been deleted. Installed bundled profiles that have not been modified since they were installed are replaced
+// when the bundled version changes. Modified profiles are never replaced; they are reported as kept and can be
+// replaced with RestoreBundledProfile.
+func SyncBundledProfiles(bundledDir string, profilesDir string, statePath string) (BundledSyncReport, error) {
+	report := BundledSyncReport{
+		Installed: make([]BundledProfileChange, 0),
+		Updated:   make([]BundledProfileChange, 0),
+		Kept:      make([]BundledProfileChange, 0),
+		Skipped:   make([]BundledProfileChange, 0),
+	}
+
+	entries, err := os.ReadDir(bundledDir)
+	if err != nil {
+		return report, fmt.Errorf("failed to read bundled profiles directory: %w", err)
+	}
+
+	if err := os.MkdirAll(profilesDir, 0755); err != nil {
+		return report, fmt.Errorf("failed to create profiles directory: %w", err)
+	}
+
+	state, err := readBundledState(statePath)
+	if err != nil {
+		return report, err
+	}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		id := entry.Name()
+		src := filepath.Join(bundledDir, id)
+		dst := filepath.Join(profilesDir, id)
+
+		bundled, err := LoadProfile(src)
+		if err != nil {
+			slog.Error("Failed to load bundled profile", "path", src, "error", err)
+			continue
+		}
+
+		bundledHash, err := hashDir(src)
+		if err != nil {
+			return report, fmt.Errorf("failed to hash bundled profile %s: %w", id, err)
+		}
+
+		change := BundledProfileChange{ID: id, Name: bundled.Details.Name, ToVersion: bundled.Details.Version}
+		record, tracked := state.Profiles[id]
+
+		// The bundled profile has changed since the profile was last synced.
+		changed := record.BundledHash != bundledHash
+		installedRecord := bundledRecord{Version: bundled.Details.Version, Hash: bundledHash, BundledHash: bundledHash}
+
+		if _, err := os.Stat(dst); errors.Is(err, fs.ErrNotExist) {
+			if tracked {
+				if changed {
+					report.Skipped = append(report.Skipped, change)
+				}
+				record.BundledHash = bundledHash
+				state.Profiles[id] = record
+				continue
+			}
+
+			if err := replaceProfileDir(src, dst); err != nil {
+				return report, fmt.Errorf("failed to install bundled profile %s: %w", id, err)
+			}
+
+			state.Profiles[id] = installedRecord
+			report.Installed = append(report.Installed, change)
+			continue
+		}
+
+		installedHash, err := hashDir(dst)
+		if err != nil {
+			return report, fmt.Errorf("failed to hash installed profile %s: %w", id, err)
+		}
+
+		if installed, err := LoadProfile(dst); err == nil {
+			change.FromVersion = installed.Details.Version
+		}
+
+		switch {
+		case installedHash == bundledHash:
+			state.Profiles[id] = installedRecord
+		case tracked && record.Hash != "" && installedHash == record.Hash:
+			if err := replaceProfileDir(src, dst); err != nil {
+				return report, fmt.Errorf("failed to update bundled profile %s: %w", id, err)
+			}
+
+			state.Profiles[id] = installedRecord
+			report.Updated = append(report.Updated, change)
+		default:
+			// Modified profiles are only reported when the bundled profile has changed since the last sync, so
+			// that a profile is not reported again on every sync.
+			if changed {
+				report.Kept = append(report.Kept, change)
+			}
+			if !tracked {
+				record.Version = change.FromVersion
+			}
+			record.BundledHash = bundledHash
+			state.Profiles[id] = record
+		}
+	}
+
+	if err := writeBundledState(statePath, state); err != nil {
+		return report, err
+	}
+
+	return report, nil
+}
+
+// RestoreBundledProfile replaces an installed bundled profile with its bundled version, discarding any changes
+// made to it. A bundled profile that has been deleted is installed again.
+func RestoreBundledProfile(bundledDir string, profilesDir string, statePath string, id string) error {
+	src := filepath.Join(bundledDir, filepath.Base(id))
+	bundled, err := LoadProfile(src)
+	if err != nil {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, id)
+	}
+
+	bundledHash, err := hashDir(src)
+	if err != nil {
+		return fmt.Errorf("failed to hash bundled profile %s: %w", id, err)
+	}
+
+	state, err := readBundledState(statePath)
+	if err != nil {
+		return err
+	}
+
+	if err := replaceProfileDir(src, filepath.Join(profilesDir, filepath.Base(id))); err != nil {
+		return fmt.Errorf("failed to restore bundled profile %s: %w", id, err)
+	}
+
+	state.Profiles[filepath.Base(id)] = bundledRecord{Version: bundled.Details.Version, Hash: bundledHash, BundledHash: bundledHash}
+	return writeBundledState(statePath, state)
+}
+
+// readBundledState reads the record of installed bundled profiles. A missing file is an empty record.
+func readBundledState(path string) (bundledState, error) {
+	state := bundledState{Profiles: make(map[string]bundledRecord)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read bundled profiles state: %w", err)
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse bundled profiles state: %w", err)
+	}
+	if state.Profiles == nil {
+		state.Profiles = make(map[string]bundledRecord)
+	}
+
+	return state, nil
+}
+
+// writeBundledState writes the record of installed bundled profiles.
+func writeBundledState(path string, state bundledState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundled profiles state: %w", err)
+	}
+
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write bundled profiles state: %w", err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to write bundled profiles state: %w", err)
+	}
+
+	return nil
+}
+
+// replaceProfileDir replaces the profile directory dst with a copy of src. The copy is made next to dst and
+// swapped in, so that dst is never left half copied.
+func replaceProfileDir(src string, dst string) error {
+	temp, err := os.MkdirTemp(filepath.Dir(dst), "."+filepath.Base(dst)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(temp)
+
+	if err := copyProfileDir(src, temp); err != nil {
+		return err
+	}
+
+	old := temp + ".old"
+	if err := os.Rename(dst, old); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	if err := os.Rename(temp, dst); err != nil {
+		// Put the previous profile back so that it is not lost.
+		os.Rename(old, dst)
+		return err
+	}
+
+	return os.RemoveAll(old)
+}
+
+// hashDir returns a hash of the relative paths and contents of the files in a directory. Hidden files, such as
+// the .DS_Store files created by the macOS Finder, are not part of the hash.
+func hashDir(dir string) (string, error) {
+	files := make([]string, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.Type().IsRegular() && !strings.HasPrefix(d.Name(), ".") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	sort.Strings(files)
+
+	hash := sha256.New()
+	for _, path := range files {
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return "", err
+		}
+
+		file, err := os.Open(path)
+		if err != nil {
+			return "", err
+		}
+
+		contents := sha256.New()
+		_, err = io.Copy(contents, file)
+		file.Close()
+		if err != nil {
+			return "", err
+		}
+
+		fmt.Fprintf(hash, "%s\x00%x\n", filepath.ToSlash(rel), contents.Sum(nil))
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,554 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// ErrSourceNotFound is returned when an edit refers to a source that the profile does not define.
+	ErrSourceNotFound = errors.New("source not found")
+	// ErrSourceExists is returned when a source is added or renamed to an ID that the profile already defines.
+	ErrSourceExists = errors.New("source already exists")
+	// ErrSourceInUse is returned when a source is removed while profiles extending the profile still use it.
+	ErrSourceInUse = errors.New("source is used by profiles extending this profile")
+)
+
+// AudioSlot is one of the audio files of a source, named after its field in profile.yaml.
+type AudioSlot string
+
+const (
+	AudioSlotPress       AudioSlot = "press"
+	AudioSlotRelease     AudioSlot = "release"
+	AudioSlotRepeat      AudioSlot = "repeat"
+	AudioSlotHold        AudioSlot = "hold"
+	AudioSlotReleaseLong AudioSlot = "release_long"
+)
+
+// Editor edits an existing profile in place. The editor works on the profile as it is written in its
+// profile.yaml, so sources and mappings inherited through Profile.Extends are not part of the edit and are not
+// written to the profile.
+//
+// Edits are only applied to disk when Save is called. Since the profile is written back from its parsed form,
+// comments in profile.yaml are not preserved.
+type Editor struct {
+	profile *Profile
+	// Whether the profile sets its ID explicitly. Profiles identified by their directory name are written back
+	// without an ID.
+	explicitID bool
+	// Audio files to copy into the profile directory when saving, by file name.
+	pendingAudio map[string]string
+}
+
+// Edit loads a profile, by ID or name, for editing. Profiles in read-only layers cannot be edited; duplicate
+// them first.
+func Edit(ref string) (*Editor, error) {
+	loaded, ok := FindProfile(ref)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, ref)
+	}
+
+	if err := checkWritable(loaded); err != nil {
+		return nil, err
+	}
+
+	p, err := LoadProfile(loaded.Location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load profile for editing: %w", err)
+	}
+
+	return &Editor{
+		profile:      p,
+		explicitID:   p.ID != filepath.Base(p.Location),
+		pendingAudio: make(map[string]string),
+	}, nil
+}
+
+// Profile returns the profile being edited, including the edits that have not been saved yet. Sources and
+// mappings inherited through Profile.Extends are not included.
+func (e *Editor) Profile() *Profile {
+	return e.profile
+}
+
+// SetDetails replaces the details of the profile, such as its name and description.
+func (e *Editor) SetDetails(details ProfileDetails) error {
+	details.Name = strings.TrimSpace(details.Name)
+	if details.Name == "" {
+		return fmt.Errorf("profile name is required")
+	}
+
+	if existing, ok := FindProfileByName(details.Name); ok && existing.ID != e.profile.ID {
+		return fmt.Errorf("%w: %s", ErrProfileNameTaken, details.Name)
+	}
+
+	e.profile.Details = details
+	return nil
+}
+
+// AddSource adds a source with the given audio files. The file names are relative to the profile directory;
+// use ReplaceAudio to add files from outside the profile.
+func (e *Editor) AddSource(id string, files SourceFiles) error {
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return fmt.Errorf("source ID is required")
+	}
+
+	if e.hasSource(id) {
+		return fmt.Errorf("%w: %s", ErrSourceExists, id)
+	}
+
+	e.profile.Sources = append(e.profile.Sources, Source{ID: id, Source: files, Location: e.profile.Location})
+	return nil
+}
+
+// RemoveSource removes a source and every reference to it. Key and button mappings that only referred to the
+// source are removed, so the keys and buttons fall back to the default sources. A source that loaded profiles
+// extending the profile map keys or buttons to cannot be removed, since they would refer to a missing source.
+func (e *Editor) RemoveSource(id string) error {
+	if !e.hasSource(id) {
+		return fmt.Errorf("%w: %s", ErrSourceNotFound, id)
+	}
+
+	if children := e.childrenUsingSource(id); len(children) > 0 {
+		return fmt.Errorf("%w: %s is used by %s", ErrSourceInUse, id, strings.Join(children, ", "))
+	}
+
+	e.profile.Sources = lo.Filter(e.profile.Sources, func(s Source, _ int) bool {
+		return s.ID != id
+	})
+
+	e.rewriteSourceReferences(func(ref string) (string, bool) {
+		return ref, ref != id
+	})
+	return nil
+}
+
+// RenameSource changes the ID of a source and updates every reference to it.
+func (e *Editor) RenameSource(oldID string, newID string) error {
+	newID = strings.TrimSpace(newID)
+	if newID == "" {
+		return fmt.Errorf("source ID is required")
+	}
+
+	if !e.hasSource(oldID) {
+		return fmt.Errorf("%w: %s", ErrSourceNotFound, oldID)
+	}
+
+	if oldID == newID {
+		return nil
+	}
+
+	if e.hasSource(newID) {
+		return fmt.Errorf("%w: %s", ErrSourceExists, newID)
+	}
+
+	for i := range e.profile.Sources {
+		if e.profile.Sources[i].ID == oldID {
+			e.profile.Sources[i].ID = newID
+		}
+	}
+
+	e.rewriteSourceReferences(func(ref string) (string, bool) {
+		return lo.Ternary(ref == oldID, newID, ref), true
+	})
+	return nil
+}
+
+// SetDefaultKeySources sets the sources played for keys that are not mapped in the Other section.
+func (e *Editor) SetDefaultKeySources(sourceIDs []string) error {
+	if err := e.checkSources(sourceIDs); err != nil {
+		return err
+	}
+
+	e.profile.Keys.Default = append(SoundRef{}, sourceIDs...)
+	return nil
+}
+
+// AssignKeys maps keys to the given sources, replacing their current mappings. Keys are removed from the
+// entries that list them by name, and entries left without keys are removed. Entries that select keys by group
+// or region, or that only apply while modifiers are held, are left unchanged. With no sources, the keys fall
+// back to the default sources.
+func (e *Editor) AssignKeys(keys []string, sourceIDs []string) error {
+	if err := e.checkSources(sourceIDs); err != nil {
+		return err
+	}
+
+	other := make([]Key, 0, len(e.profile.Keys.Other)+1)
+	for _, entry := range e.profile.Keys.Other {
+		if entry.Keys == nil || len(entry.WhenModifiers) > 0 {
+			other = append(other, entry)
+			continue
+		}
+
+		remaining := lo.Reject(*entry.Keys, func(name string, _ int) bool {
+			return lo.ContainsBy(keys, func(k string) bool { return strings.EqualFold(k, name) })
+		})
+		if len(remaining) == 0 {
+			continue
+		}
+
+		entry.Keys = &remaining
+		other = append(other, entry)
+	}
+
+	if len(keys) > 0 && len(sourceIDs) > 0 {
+		assigned := append([]string{}, keys...)
+		other = append(other, Key{Sound: append(SoundRef{}, sourceIDs...), Keys: &assigned})
+	}
+
+	e.profile.Keys.Other = other
+	return nil
+}
+
+// SetDefaultButtonSources sets the sources played for buttons that are not mapped in the Other section.
+func (e *Editor) SetDefaultButtonSources(sourceIDs []string) error {
+	if err := e.checkSources(sourceIDs); err != nil {
+		return err
+	}
+
+	e.profile.Buttons.Default = append(SoundRef{}, sourceIDs...)
+	return nil
+}
+
+// AssignButtons maps buttons to the given sources, replacing their current mappings. Entries left without
+// buttons are removed. With no sources, the buttons fall back to the default sources.
+func (e *Editor) AssignButtons(buttons []string, sourceIDs []string) error {
+	if err := e.checkSources(sourceIDs); err != nil {
+		return err
+	}
+
+	other := make([]Button, 0, len(e.profile.Buttons.Other)+1)
+	for _, entry := range e.profile.Buttons.Other {
+		if entry.Buttons == nil {
+			other = append(other, entry)
+			continue
+		}
+
+		remaining := lo.Reject(*entry.Buttons, func(name string, _ int) bool {
+			return lo.ContainsBy(buttons, func(b string) bool { return strings.EqualFold(b, name) })
+		})
+		if len(remaining) == 0 {
+			continue
+		}
+
+		entry.Buttons = &remaining
+		other = append(other, entry)
+	}
+
+	if len(buttons) > 0 && len(sourceIDs) > 0 {
+		assigned := append([]string{}, buttons...)
+		other = append(other, Button{Sound: append(SoundRef{}, sourceIDs...), Buttons: &assigned})
+	}
+
+	e.profile.Buttons.Other = other
+	return nil
+}
+
+// ReplaceAudio sets one of the audio files of a source to the audio file at the given path. The file is
+// copied into the profile directory when the profile is saved. Existing files are never overwritten, so a file
+// whose name is already used in the profile directory is copied under a new name. An empty path removes the
+// audio file from the source.
+func (e *Editor) ReplaceAudio(sourceID string, slot AudioSlot, path string) error {
+	index := lo.IndexOf(lo.Map(e.profile.Sources, func(s Source, _ int) string { return s.ID }), sourceID)
+	if index < 0 {
+		return fmt.Errorf("%w: %s", ErrSourceNotFound, sourceID)
+	}
+
+	switch slot {
+	case AudioSlotPress, AudioSlotRelease, AudioSlotRepeat, AudioSlotHold, AudioSlotReleaseLong:
+	default:
+		return fmt.Errorf("unknown audio slot: %s", slot)
+	}
+
+	file := e.profile.Sources[index].Source.slotFile(slot)
+	if path == "" {
+		if slot == AudioSlotPress {
+			return fmt.Errorf("a source must have a press audio file")
+		}
+		*file = nil
+		return nil
+	}
+
+	if !isAudioFile(path) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedAudioFormat, filepath.Ext(path))
+	}
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("failed to read audio file: %w", err)
+	}
+
+	name := e.availableFileName(filepath.Base(path))
+	e.pendingAudio[name] = path
+
+	*file = &name
+	return nil
+}
+
+// Save validates the edited profile and writes it back to its directory. New audio files are copied into the
+// directory first, and profile.yaml is then replaced atomically, so the profile on disk is never left half
+// written. If the edited profile is invalid, nothing is changed. The loaded profiles are reloaded after saving.
+func (e *Editor) Save() (*Profile, error) {
+	dir := e.profile.Location
+
+	data, err := e.marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	copied := make([]string, 0, len(e.pendingAudio))
+	removeCopied := func() {
+		for _, path := range copied {
+			os.Remove(path)
+		}
+	}
+
+	for name, src := range e.pendingAudio {
+		dst := filepath.Join(dir, name)
+		if err := copyNewFile(src, dst); err != nil {
+			removeCopied()
+			return nil, fmt.Errorf("failed to copy audio file %s: %w", name, err)
+		}
+		copied = append(copied, dst)
+	}
+
+	edited, _, _, err := parseProfile(data)
+	if err != nil {
+		removeCopied()
+		return nil, err
+	}
+	edited.setLocation(e.profile.FS, dir)
+	edited.ID = e.profile.ID
+
+	resolved, err := resolveAgainstLoaded(edited)
+	if err != nil {
+		removeCopied()
+		return nil, fmt.Errorf("failed to resolve edited profile: %w", err)
+	}
+	if resolved.Details.DeviceType == "" {
+		resolved.Details.DeviceType = DeviceTypeKeyboard
+	}
+	if err := Validate(resolved).Err(); err != nil {
+		removeCopied()
+		return nil, err
+	}
+
+	path := filepath.Join(dir, "profile.yaml")
+	tempPath := path + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		removeCopied()
+		return nil, fmt.Errorf("failed to write profile metadata: %w", err)
+	}
+
+	if err := os.Rename(tempPath, path); err != nil {
+		os.Remove(tempPath)
+		removeCopied()
+		return nil, fmt.Errorf("failed to replace profile metadata: %w", err)
+	}
+
+	e.pendingAudio = make(map[string]string)
+
+	if err := LoadProfiles(); err != nil {
+		return nil, fmt.Errorf("failed to reload profiles after edit: %w", err)
+	}
+
+	saved, ok := FindProfileByID(e.profile.ID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, e.profile.ID)
+	}
+
+	return saved, nil
+}
+
+// marshal returns the contents of the profile.yaml file of the edited profile. Sections that the profile does
+// not use, such as the buttons of a keyboard profile, are omitted.
+func (e *Editor) marshal() ([]byte, error) {
+	p := *e.profile
+	if !e.explicitID {
+		p.ID = ""
+	}
+
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal profile metadata: %w", err)
+	}
+
+	var doc yaml.MapSlice
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to marshal profile metadata: %w", err)
+	}
+
+	if p.Details.DeviceType == "" {
+		details, _ := lo.Find(doc, func(item yaml.MapItem) bool { return item.Key == "profile" })
+		section, _ := details.Value.(yaml.MapSlice)
+		doc = setDocumentValue(doc, "profile", lo.Reject(section, func(item yaml.MapItem, _ int) bool {
+			return item.Key == "device"
+		}))
+	}
+
+	doc = lo.Reject(doc, func(item yaml.MapItem, _ int) bool {
+		switch item.Key {
+		case "keys":
+			return len(p.Keys.Default) == 0 && len(p.Keys.Other) == 0
+		case "buttons":
+			return len(p.Buttons.Default) == 0 && len(p.Buttons.Other) == 0
+		case "sources":
+			return len(p.Sources) == 0 && p.Extends != ""
+		}
+		return false
+	})
+
+	data, err = yaml.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal profile metadata: %w", err)
+	}
+
+	return data, nil
+}
+
+// rewriteSourceReferences rewrites the source IDs referenced by the key and button mappings. The rewrite
+// returns the new ID and whether to keep the reference. Mappings left without sources are removed.
+func (e *Editor) rewriteSourceReferences(rewrite func(string) (string, bool)) {
+	rewriteAll := func(ids SoundRef) SoundRef {
+		return lo.FilterMap(ids, func(id string, _ int) (string, bool) {
+			return rewrite(id)
+		})
+	}
+
+	e.profile.Keys.Default = rewriteAll(e.profile.Keys.Default)
+
+	e.profile.Keys.Other = lo.FilterMap(e.profile.Keys.Other, func(entry Key, _ int) (Key, bool) {
+		entry.Sound = rewriteAll(entry.Sound)
+		return entry, len(entry.Sound) > 0
+	})
+
+	e.profile.Buttons.Default = rewriteAll(e.profile.Buttons.Default)
+
+	e.profile.Buttons.Other = lo.FilterMap(e.profile.Buttons.Other, func(entry Button, _ int) (Button, bool) {
+		entry.Sound = rewriteAll(entry.Sound)
+		return entry, len(entry.Sound) > 0
+	})
+}
+
+// childrenUsingSource returns the names of the loaded profiles that extend the profile, directly or through
+// another profile, and map keys or buttons in their own profile.yaml to the source with the given ID that they
+// inherit from it.
+func (e *Editor) childrenUsingSource(id string) []string {
+	return lo.FilterMap(defaultStore.Profiles(), func(p *Profile, _ int) (string, bool) {
+		if !lo.Contains(p.ParentLocations, e.profile.Location) {
+			return "", false
+		}
+
+		// Children that define a source with the same ID, or inherit it from a profile in between, do not use
+		// the source of this profile.
+		source, ok := lo.Find(p.Sources, func(s Source) bool { return s.ID == id })
+		if !ok || source.Location != e.profile.Location {
+			return "", false
+		}
+
+		own, err := loadProfile(p.FS, p.Location, false)
+		if err != nil {
+			return "", false
+		}
+
+		refs := append(append(SoundRef{}, own.Keys.Default...), own.Buttons.Default...)
+		for _, entry := range own.Keys.Other {
+			refs = append(refs, entry.Sound...)
+		}
+		for _, entry := range own.Buttons.Other {
+			refs = append(refs, entry.Sound...)
+		}
+
+		return p.Details.Name, lo.Contains(refs, id)
+	})
+}
+
+// hasSource returns true if the profile defines a source with the given ID.
+func (e *Editor) hasSource(id string) bool {
+	return lo.ContainsBy(e.profile.Sources, func(s Source) bool {
+		return s.ID == id
+	})
+}
+
+// checkSources returns an error if any of the source IDs is not defined by the profile. Sources inherited
+// through Profile.Extends may be referenced as well.
+func (e *Editor) checkSources(ids []string) error {
+	var parent *Profile
+	if e.profile.Extends != "" {
+		parent, _ = FindProfile(e.profile.Extends)
+	}
+
+	for _, id := range ids {
+		if e.hasSource(id) {
+			continue
+		}
+		if parent != nil && lo.ContainsBy(parent.Sources, func(s Source) bool { return s.ID == id }) {
+			continue
+		}
+		return fmt.Errorf("%w: %s", ErrSourceNotFound, id)
+	}
+
+	return nil
+}
+
+// availableFileName returns a file name based on name that is not used by a file in the profile directory or
+// by another pending audio file.
+func (e *Editor) availableFileName(name string) string {
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+
+	candidate := name
+	for i := 1; ; i++ {
+		_, pending := e.pendingAudio[candidate]
+		_, err := os.Stat(filepath.Join(e.profile.Location, candidate))
+		if !pending && errors.Is(err, os.ErrNotExist) {
+			return candidate
+		}
+		candidate = fmt.Sprintf("%s-%d%s", base, i, ext)
+	}
+}
+
+// slotFile returns the field of the audio file for the given slot.
+func (f *SourceFiles) slotFile(slot AudioSlot) **string {
+	switch slot {
+	case AudioSlotRelease:
+		return &f.Release
+	case AudioSlotRepeat:
+		return &f.Repeat
+	case AudioSlotHold:
+		return &f.Hold
+	case AudioSlotReleaseLong:
+		return &f.ReleaseLong
+	default:
+		return &f.Press
+	}
+}
+
+// copyNewFile copies the file at src to dst, failing if dst already exists.
+func copyNewFile(src string, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(out, in)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dst)
+	}
+
+	return err
+}
@@ -0,0 +1,69 @@
+package profile
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEditorRemoveSourceUsedByChild(t *testing.T) {
+	profilesDir := t.TempDir()
+	profiles := map[string]string{
+		"parent": `
+profile:
+  name: Parent
+sources:
+  - id: press
+    source: press.wav
+  - id: enter
+    source: enter.wav
+keys:
+  default: [press]
+`,
+		"child": `
+profile:
+  name: Child
+extends: parent
+keys:
+  other:
+    - sound: enter
+      keys: [enter]
+`,
+	}
+	for id, data := range profiles {
+		if err := os.MkdirAll(filepath.Join(profilesDir, id), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(profilesDir, id, "profile.yaml"), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	previousLayers := defaultStore.Layers()
+	t.Cleanup(func() {
+		defaultStore.SetLayers(previousLayers...)
+		defaultStore.Load()
+	})
+	defaultStore.SetLayers(Layer{Name: "user", Dir: profilesDir})
+	if err := defaultStore.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	editor, err := Edit("parent")
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+
+	if err := editor.RemoveSource("enter"); !errors.Is(err, ErrSourceInUse) {
+		t.Errorf("RemoveSource(enter) error = %v, want %v", err, ErrSourceInUse)
+	}
+	if !editor.hasSource("enter") {
+		t.Errorf("source used by the child was removed")
+	}
+
+	// The child only uses press through the default it inherits, which is removed along with the source.
+	if err := editor.RemoveSource("press"); err != nil {
+		t.Errorf("RemoveSource(press) error = %v", err)
+	}
+}
@@ -0,0 +1,275 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/key"
+	"github.com/samber/lo"
+)
+
+var (
+	// ErrParentProfileNotFound is returned when the profile named in the extends field of a profile does not exist.
+	ErrParentProfileNotFound = errors.New("parent profile not found")
+	// ErrExtendsCycle is returned when the extends fields of a set of profiles form a cycle.
+	ErrExtendsCycle = errors.New("profile inheritance cycle")
+)
+
+// resolveExtends resolves the extends field of each of the given profiles, merging each profile with its parent.
+// Profiles that cannot be resolved are logged and omitted from the result.
+func resolveExtends(loaded []*Profile) []*Profile {
+	resolved := make(map[*Profile]*Profile, len(loaded))
+	result := make([]*Profile, 0, len(loaded))
+
+	for _, p := range loaded {
+		rp, err := resolveProfile(p, loaded, resolved, nil)
+		if err != nil {
+			slog.Error("Failed to resolve profile", "path", p.Location, "error", err)
+			continue
+		}
+
+		result = append(result, rp)
+	}
+
+	return result
+}
+
+// resolveProfile resolves a single profile against the list of all loaded profiles. The chain contains the
+// profiles that are currently being resolved and is used to detect cycles.
+func resolveProfile(p *Profile, all []*Profile, resolved map[*Profile]*Profile, chain []*Profile) (*Profile, error) {
+	if rp, ok := resolved[p]; ok {
+		return rp, nil
+	}
+
+	if p.Extends == "" {
+		resolved[p] = p
+		return p, nil
+	}
+
+	if lo.Contains(chain, p) {
+		names := lo.Map(append(chain, p), func(c *Profile, _ int) string {
+			return c.Details.Name
+		})
+		return nil, fmt.Errorf("%w: %s", ErrExtendsCycle, strings.Join(names, " -> "))
+	}
+
+	parent, ok := lo.Find(all, func(c *Profile) bool {
+		return c.ID == p.Extends
+	})
+	if !ok {
+		parent, ok = lo.Find(all, func(c *Profile) bool {
+			return strings.EqualFold(c.Details.Name, p.Extends)
+		})
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrParentProfileNotFound, p.Extends)
+	}
+
+	resolvedParent, err := resolveProfile(parent, all, resolved, append(chain, p))
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve parent profile %s: %w", p.Extends, err)
+	}
+
+	merged := mergeProfiles(resolvedParent, p)
+	resolved[p] = merged
+
+	return merged, nil
+}
+
+// resolveAgainstLoaded resolves the extends field of a profile that is not one of the loaded profiles, such as a
+// profile that is being imported, using the loaded profiles as candidate parents.
+func resolveAgainstLoaded(p *Profile) (*Profile, error) {
+	if p.Extends == "" {
+		return p, nil
+	}
+
+	parent, ok := FindProfile(p.Extends)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrParentProfileNotFound, p.Extends)
+	}
+
+	return mergeProfiles(parent, p), nil
+}
+
+// mergeProfiles returns a copy of child with everything it does not define itself inherited from parent.
+//
+// The author, description and its translations, device, tags, switch type, license and homepage are inherited
+// when the child does not set them. Sources are inherited unless the child defines a source with the same ID. Key and button mappings in the
+// Other sections are inherited for every key or button that the child does not map itself, by name or, for keys,
+// by a selector such as "@letters", with key mappings only overridden by child mappings with the same modifier
+// condition, and the defaults are inherited when the
+// child does not set them. Button mappings without buttons are inherited as they are. The device type of the
+// parent is recorded so that validation can reject a child with a different device type, and the locations of
+// the parent and its own parents are recorded so that changes to them can be detected.
+func mergeProfiles(parent *Profile, child *Profile) *Profile {
+	merged := *child
+
+	if merged.Details.Author == "" {
+		merged.Details.Author = parent.Details.Author
+	}
+	if merged.Details.Description == "" {
+		merged.Details.Description = parent.Details.Description
+		if merged.Details.DescriptionI18n == nil {
+			merged.Details.DescriptionI18n = parent.Details.DescriptionI18n
+		}
+	}
+	if merged.Details.DeviceType == "" {
+		merged.Details.DeviceType = parent.Details.DeviceType
+	}
+	merged.parentDeviceType = effectiveDeviceType(parent.Details.DeviceType)
+	merged.ParentLocations = append([]string{parent.Location}, parent.ParentLocations...)
+	if merged.Details.Tags == nil {
+		merged.Details.Tags = parent.Details.Tags
+	}
+	if merged.Details.SwitchType == "" {
+		merged.Details.SwitchType = parent.Details.SwitchType
+	}
+	if merged.Details.License == "" {
+		merged.Details.License = parent.Details.License
+	}
+	if merged.Details.Homepage == "" {
+		merged.Details.Homepage = parent.Details.Homepage
+	}
+
+	// Sources
+	sources := lo.Filter(parent.Sources, func(s Source, _ int) bool {
+		return !lo.ContainsBy(child.Sources, func(c Source) bool {
+			return c.ID == s.ID
+		})
+	})
+	merged.Sources = append(sources, child.Sources...)
+
+	// Keys
+	if len(child.Keys.Default) == 0 {
+		merged.Keys.Default = parent.Keys.Default
+	}
+	otherKeys := make([]Key, 0, len(parent.Keys.Other)+len(child.Keys.Other))
+	for _, k := range parent.Keys.Other {
+		// A child entry only overrides parent entries with the same modifier condition.
+		overrides := lo.Filter(child.Keys.Other, func(c Key, _ int) bool {
+			return sameModifiers(c.WhenModifiers, k.WhenModifiers)
+		})
+
+		if k.Keys == nil || len(*k.Keys) == 0 {
+			// Conditional entries without keys apply to every key.
+			if len(k.WhenModifiers) == 0 || lo.ContainsBy(overrides, func(c Key) bool {
+				return c.Keys == nil || len(*c.Keys) == 0
+			}) {
+				continue
+			}
+
+			otherKeys = append(otherKeys, k)
+			continue
+		}
+
+		childKeys := lo.FlatMap(overrides, func(c Key, _ int) []string {
+			return lo.FromPtr(c.Keys)
+		})
+		keys := withoutKeys(*k.Keys, childKeys)
+		if len(keys) == 0 {
+			continue
+		}
+
+		otherKeys = append(otherKeys, Key{Sound: k.Sound, Keys: &keys, WhenModifiers: k.WhenModifiers})
+	}
+	merged.Keys.Other = append(otherKeys, child.Keys.Other...)
+
+	// Buttons
+	if len(child.Buttons.Default) == 0 {
+		merged.Buttons.Default = parent.Buttons.Default
+	}
+	childButtons := lo.FlatMap(child.Buttons.Other, func(b Button, _ int) []string {
+		return lo.FromPtr(b.Buttons)
+	})
+	otherButtons := make([]Button, 0, len(parent.Buttons.Other)+len(child.Buttons.Other))
+	for _, b := range parent.Buttons.Other {
+		// Entries without buttons are kept as they are, so that validation still reports them.
+		if b.Buttons == nil || len(*b.Buttons) == 0 {
+			otherButtons = append(otherButtons, b)
+			continue
+		}
+
+		buttons := withoutNames(*b.Buttons, childButtons)
+		if len(buttons) == 0 {
+			continue
+		}
+
+		otherButtons = append(otherButtons, Button{Sound: b.Sound, Buttons: &buttons})
+	}
+	merged.Buttons.Other = append(otherButtons, child.Buttons.Other...)
+
+	return &merged
+}
+
+// sameModifiers returns true if two modifier conditions list the same modifiers, ignoring order and case.
+func sameModifiers(a []string, b []string) bool {
+	normalize := func(names []string) []string {
+		return lo.Uniq(lo.Map(names, func(name string, _ int) string {
+			return strings.ToLower(strings.TrimSpace(name))
+		}))
+	}
+
+	normalizedA, normalizedB := normalize(a), normalize(b)
+	return len(normalizedA) == len(normalizedB) && lo.Every(normalizedA, normalizedB)
+}
+
+// withoutNames returns the names that are not in exclude, compared case-insensitively.
+func withoutNames(names []string, exclude []string) []string {
+	return lo.Filter(names, func(name string, _ int) bool {
+		return !lo.ContainsBy(exclude, func(e string) bool {
+			return strings.EqualFold(e, name)
+		})
+	})
+}
+
+// withoutKeys returns the key names that are not in exclude, compared case-insensitively, and that are not
+// selected by a selector in exclude. Selectors in names are only excluded by the same selector.
+func withoutKeys(names []string, exclude []string) []string {
+	selectors := lo.FilterMap(exclude, func(name string, _ int) (key.Selector, bool) {
+		if !key.IsSelector(name) {
+			return key.Selector{}, false
+		}
+
+		selector, err := key.ParseSelector(name)
+		return selector, err == nil
+	})
+
+	return lo.Filter(withoutNames(names, exclude), func(name string, _ int) bool {
+		k, ok := findKeyByNameOrCode(name)
+		return !ok || !lo.SomeBy(selectors, func(selector key.Selector) bool {
+			return selector.Matches(k)
+		})
+	})
+}
+
+// findKeyByNameOrCode returns the key with the given name or numeric key code, and whether the name refers to a
+// key rather than a selector.
+func findKeyByNameOrCode(name string) (key.Key, bool) {
+	if key.IsSelector(name) {
+		return key.Key{}, false
+	}
+
+	if k, ok := key.FindKey(name); ok {
+		return k, true
+	}
+
+	code, err := strconv.ParseUint(name, 10, 32)
+	if err != nil {
+		return key.Key{}, false
+	}
+
+	return key.FindKeyCode(uint32(code)), true
+}
+
+// effectiveDeviceType returns the device type that a profile with the given device type is used as. Profiles
+// that do not set a device type are keyboard profiles.
+func effectiveDeviceType(deviceType DeviceType) DeviceType {
+	if deviceType == "" {
+		return DeviceTypeKeyboard
+	}
+
+	return deviceType
+}
@@ -0,0 +1,317 @@
+package profile
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v2"
+)
+
+func mustUnmarshalProfile(t *testing.T, data string) *Profile {
+	t.Helper()
+
+	var p Profile
+	if err := yaml.Unmarshal([]byte(data), &p); err != nil {
+		t.Fatalf("failed to unmarshal profile: %v", err)
+	}
+
+	return &p
+}
+
+func TestMergeProfilesSources(t *testing.T) {
+	parent := mustUnmarshalProfile(t, `
+profile:
+  name: Parent
+sources:
+  - id: press
+    source: parent-press.wav
+  - id: space
+    source: parent-space.wav
+`)
+	child := mustUnmarshalProfile(t, `
+profile:
+  name: Child
+extends: Parent
+sources:
+  - id: press
+    source: child-press.wav
+  - id: enter
+    source: child-enter.wav
+`)
+
+	merged := mergeProfiles(parent, child)
+
+	got := lo.Map(merged.Sources, func(s Source, _ int) [2]string {
+		return [2]string{s.ID, lo.FromPtr(s.Source.Press)}
+	})
+	want := [][2]string{
+		{"space", "parent-space.wav"},
+		{"press", "child-press.wav"},
+		{"enter", "child-enter.wav"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("sources = %v, want %v", got, want)
+	}
+}
+
+func TestMergeProfilesKeys(t *testing.T) {
+	parent := mustUnmarshalProfile(t, `
+profile:
+  name: Parent
+keys:
+  default: [press]
+  other:
+    - sound: space
+      keys: [space, enter]
+    - sound: shifted
+      keys: [a]
+      when_modifiers: [shift]
+    - sound: ctrl
+      when_modifiers: [ctrl]
+`)
+
+	tests := []struct {
+		name        string
+		child       string
+		wantDefault SoundRef
+		wantOther   []Key
+	}{
+		{
+			name: "inherits everything",
+			child: `
+profile:
+  name: Child
+extends: Parent
+`,
+			wantDefault: SoundRef{"press"},
+			wantOther: []Key{
+				{Sound: SoundRef{"space"}, Keys: &[]string{"space", "enter"}},
+				{Sound: SoundRef{"shifted"}, Keys: &[]string{"a"}, WhenModifiers: []string{"shift"}},
+				{Sound: SoundRef{"ctrl"}, WhenModifiers: []string{"ctrl"}},
+			},
+		},
+		{
+			name: "overrides default and keys without modifiers",
+			child: `
+profile:
+  name: Child
+extends: Parent
+keys:
+  default: [child-press]
+  other:
+    - sound: child-enter
+      keys: [enter, a]
+`,
+			wantDefault: SoundRef{"child-press"},
+			wantOther: []Key{
+				{Sound: SoundRef{"space"}, Keys: &[]string{"space"}},
+				{Sound: SoundRef{"shifted"}, Keys: &[]string{"a"}, WhenModifiers: []string{"shift"}},
+				{Sound: SoundRef{"ctrl"}, WhenModifiers: []string{"ctrl"}},
+				{Sound: SoundRef{"child-enter"}, Keys: &[]string{"enter", "a"}},
+			},
+		},
+		{
+			name: "overrides keys with the same modifiers",
+			child: `
+profile:
+  name: Child
+extends: Parent
+keys:
+  other:
+    - sound: child-shifted
+      keys: [a]
+      when_modifiers: [shift]
+    - sound: child-ctrl
+      when_modifiers: [ctrl]
+`,
+			wantDefault: SoundRef{"press"},
+			wantOther: []Key{
+				{Sound: SoundRef{"space"}, Keys: &[]string{"space", "enter"}},
+				{Sound: SoundRef{"child-shifted"}, Keys: &[]string{"a"}, WhenModifiers: []string{"shift"}},
+				{Sound: SoundRef{"child-ctrl"}, WhenModifiers: []string{"ctrl"}},
+			},
+		},
+		{
+			name: "selector overrides exact keys",
+			child: `
+profile:
+  name: Child
+extends: Parent
+keys:
+  other:
+    - sound: child-arrows
+      keys: ["@arrows"]
+    - sound: child-shifted-letters
+      keys: ["@letters"]
+      when_modifiers: [shift]
+`,
+			wantDefault: SoundRef{"press"},
+			wantOther: []Key{
+				{Sound: SoundRef{"space"}, Keys: &[]string{"space", "enter"}},
+				{Sound: SoundRef{"ctrl"}, WhenModifiers: []string{"ctrl"}},
+				{Sound: SoundRef{"child-arrows"}, Keys: &[]string{"@arrows"}},
+				{Sound: SoundRef{"child-shifted-letters"}, Keys: &[]string{"@letters"}, WhenModifiers: []string{"shift"}},
+			},
+		},
+		{
+			name: "selector overrides only the keys it selects",
+			child: `
+profile:
+  name: Child
+extends: Parent
+keys:
+  other:
+    - sound: child-letters
+      keys: ["@letters"]
+`,
+			wantDefault: SoundRef{"press"},
+			wantOther: []Key{
+				{Sound: SoundRef{"space"}, Keys: &[]string{"space", "enter"}},
+				{Sound: SoundRef{"shifted"}, Keys: &[]string{"a"}, WhenModifiers: []string{"shift"}},
+				{Sound: SoundRef{"ctrl"}, WhenModifiers: []string{"ctrl"}},
+				{Sound: SoundRef{"child-letters"}, Keys: &[]string{"@letters"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := mergeProfiles(parent, mustUnmarshalProfile(t, tt.child))
+
+			if !reflect.DeepEqual(merged.Keys.Default, tt.wantDefault) {
+				t.Errorf("default keys = %v, want %v", merged.Keys.Default, tt.wantDefault)
+			}
+			if !reflect.DeepEqual(merged.Keys.Other, tt.wantOther) {
+				t.Errorf("other keys = %+v, want %+v", merged.Keys.Other, tt.wantOther)
+			}
+		})
+	}
+}
+
+func TestMergeProfilesButtons(t *testing.T) {
+	parent := mustUnmarshalProfile(t, `
+profile:
+  name: Parent
+  device: mouse
+buttons:
+  default: [click]
+  other:
+    - sound: right
+      buttons: [right, middle]
+    - sound: unassigned
+`)
+
+	tests := []struct {
+		name        string
+		child       string
+		wantDefault SoundRef
+		wantOther   []Button
+	}{
+		{
+			name: "inherits everything",
+			child: `
+profile:
+  name: Child
+extends: Parent
+`,
+			wantDefault: SoundRef{"click"},
+			wantOther: []Button{
+				{Sound: SoundRef{"right"}, Buttons: &[]string{"right", "middle"}},
+				{Sound: SoundRef{"unassigned"}},
+			},
+		},
+		{
+			name: "overrides default and buttons",
+			child: `
+profile:
+  name: Child
+  device: mouse
+extends: Parent
+buttons:
+  default: [child-click]
+  other:
+    - sound: child-middle
+      buttons: [middle]
+`,
+			wantDefault: SoundRef{"child-click"},
+			wantOther: []Button{
+				{Sound: SoundRef{"right"}, Buttons: &[]string{"right"}},
+				{Sound: SoundRef{"unassigned"}},
+				{Sound: SoundRef{"child-middle"}, Buttons: &[]string{"middle"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := mergeProfiles(parent, mustUnmarshalProfile(t, tt.child))
+
+			if !reflect.DeepEqual(merged.Buttons.Default, tt.wantDefault) {
+				t.Errorf("default buttons = %v, want %v", merged.Buttons.Default, tt.wantDefault)
+			}
+			if !reflect.DeepEqual(merged.Buttons.Other, tt.wantOther) {
+				t.Errorf("other buttons = %+v, want %+v", merged.Buttons.Other, tt.wantOther)
+			}
+		})
+	}
+}
+
+func TestMergeProfilesDeviceType(t *testing.T) {
+	tests := []struct {
+		name       string
+		parent     DeviceType
+		child      DeviceType
+		wantDevice DeviceType
+		wantError  bool
+	}{
+		{name: "inherited", parent: DeviceTypeMouse, child: "", wantDevice: DeviceTypeMouse},
+		{name: "same", parent: DeviceTypeMouse, child: DeviceTypeMouse, wantDevice: DeviceTypeMouse},
+		{name: "parent defaults to keyboard", parent: "", child: DeviceTypeKeyboard, wantDevice: DeviceTypeKeyboard},
+		{name: "keyboard extends mouse", parent: DeviceTypeMouse, child: DeviceTypeKeyboard, wantDevice: DeviceTypeKeyboard, wantError: true},
+		{name: "mouse extends keyboard", parent: "", child: DeviceTypeMouse, wantDevice: DeviceTypeMouse, wantError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := &Profile{Details: ProfileDetails{Name: "Parent", DeviceType: tt.parent}}
+			child := &Profile{Details: ProfileDetails{Name: "Child", DeviceType: tt.child}, Extends: "Parent"}
+
+			merged := mergeProfiles(parent, child)
+			if merged.Details.DeviceType != tt.wantDevice {
+				t.Errorf("device type = %q, want %q", merged.Details.DeviceType, tt.wantDevice)
+			}
+
+			result := Validate(merged)
+			mismatch := lo.ContainsBy(result.Errors, func(d Diagnostic) bool {
+				return d.Field == "profile.device"
+			})
+			if mismatch != tt.wantError {
+				t.Errorf("device type error = %v, want %v (errors: %v)", mismatch, tt.wantError, result.Errors)
+			}
+		})
+	}
+}
+
+func TestResolveExtendsRecordsParentLocations(t *testing.T) {
+	grandparent := mustUnmarshalProfile(t, "id: grandparent\nprofile:\n  name: Grandparent\n")
+	grandparent.Location = "profiles/grandparent"
+	parent := mustUnmarshalProfile(t, "id: parent\nprofile:\n  name: Parent\nextends: grandparent\n")
+	parent.Location = "profiles/parent"
+	child := mustUnmarshalProfile(t, "id: child\nprofile:\n  name: Child\nextends: parent\n")
+	child.Location = "profiles/child"
+
+	resolved := resolveExtends([]*Profile{child, parent, grandparent})
+
+	got := lo.Map(resolved, func(p *Profile, _ int) []string {
+		return p.ParentLocations
+	})
+	want := [][]string{
+		{"profiles/parent", "profiles/grandparent"},
+		{"profiles/grandparent"},
+		nil,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parent locations = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,272 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// ErrProfileNotFound is returned when a profile reference does not match any loaded profile.
+	ErrProfileNotFound = errors.New("profile not found")
+	// ErrProfileNameTaken is returned when a profile name is already used by another profile.
+	ErrProfileNameTaken = errors.New("profile name is already in use")
+)
+
+// FindProfileByID finds a profile by its ID.
+func FindProfileByID(id string) (*Profile, bool) {
+	return defaultStore.FindByID(id)
+}
+
+// FindProfile finds a profile by reference. The reference is matched against profile IDs first and profile
+// names second, so that references stored before profiles had stable IDs keep working.
+func FindProfile(ref string) (*Profile, bool) {
+	if profile, ok := FindProfileByID(ref); ok {
+		return profile, true
+	}
+
+	return FindProfileByName(ref)
+}
+
+// ResolveProfileID returns the ID of the profile that ref refers to, or ref itself if it does not refer to any
+// loaded profile.
+func ResolveProfileID(ref string) string {
+	if profile, ok := FindProfile(ref); ok {
+		return profile.ID
+	}
+
+	return ref
+}
+
+// RenameProfile changes the name of a profile. The ID of the profile does not change, so references to the
+// profile by ID remain valid. Profiles that extend the renamed profile by name are updated to use the new name.
+// Profiles in read-only layers cannot be renamed, and neither can profiles that a read-only profile extends by
+// name.
+func RenameProfile(ref string, newName string) (*Profile, error) {
+	profile, ok := FindProfile(ref)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, ref)
+	}
+
+	if err := checkWritable(profile); err != nil {
+		return nil, err
+	}
+
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return nil, fmt.Errorf("profile name is required")
+	}
+
+	if existing, ok := FindProfileByName(newName); ok && existing != profile {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNameTaken, newName)
+	}
+
+	oldName := profile.Details.Name
+	children := lo.Filter(defaultStore.Profiles(), func(p *Profile, _ int) bool {
+		return strings.EqualFold(p.Extends, oldName)
+	})
+
+	// A read-only child could not be updated and would lose its parent.
+	for _, child := range children {
+		if child.ReadOnly {
+			return nil, fmt.Errorf("%w: %s extends %s by name", ErrReadOnlyProfile, child.Details.Name, oldName)
+		}
+	}
+
+	err := updateProfileDocument(profile.Location, func(doc yaml.MapSlice) yaml.MapSlice {
+		return setDetailsValue(doc, "name", newName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, child := range children {
+		err = updateProfileDocument(child.Location, func(doc yaml.MapSlice) yaml.MapSlice {
+			return setDocumentValue(doc, "extends", newName)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update profile %s extending %s: %w", child.Details.Name, oldName, err)
+		}
+	}
+
+	err = LoadProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload profiles after rename: %w", err)
+	}
+
+	renamed, ok := FindProfileByID(profile.ID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, profile.ID)
+	}
+
+	return renamed, nil
+}
+
+// DuplicateProfile copies a profile to a new profile with the given name and a new ID. The copy is stored in
+// the first writable layer, so duplicating is how a profile in a read-only layer, or one loaded from an embedded
+// file system or archive, is customized.
+func DuplicateProfile(ref string, newName string) (*Profile, error) {
+	profilesDir, err := defaultStore.WritableDir()
+	if err != nil {
+		return nil, err
+	}
+
+	profile, ok := FindProfile(ref)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, ref)
+	}
+
+	newName = strings.TrimSpace(newName)
+	if newName == "" {
+		return nil, fmt.Errorf("profile name is required")
+	}
+
+	if _, ok := FindProfileByName(newName); ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNameTaken, newName)
+	}
+
+	// The profile is copied and updated next to its final location and moved into place, so that the copy is
+	// never loaded with the ID and name of the original.
+	tempDir, err := os.MkdirTemp(profilesDir, ".profile-duplicate-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	err = copyProfileFS(profile.FS, tempDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy profile: %w", err)
+	}
+
+	// The copy must not keep an explicit ID from the original, otherwise both profiles would share it.
+	err = updateProfileDocument(tempDir, func(doc yaml.MapSlice) yaml.MapSlice {
+		doc = lo.Filter(doc, func(item yaml.MapItem, _ int) bool {
+			return item.Key != "id"
+		})
+		return setDetailsValue(doc, "name", newName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New().String()
+	err = os.Rename(tempDir, filepath.Join(profilesDir, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to move profile to final location: %w", err)
+	}
+
+	err = LoadProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload profiles after duplicate: %w", err)
+	}
+
+	duplicate, ok := FindProfileByID(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, id)
+	}
+
+	return duplicate, nil
+}
+
+// updateProfileDocument applies an update to the raw profile.yaml document in the given profile directory and
+// writes it back atomically.
+func updateProfileDocument(dir string, update func(yaml.MapSlice) yaml.MapSlice) error {
+	path := filepath.Join(dir, "profile.yaml")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read profile metadata file: %w", err)
+	}
+
+	var doc yaml.MapSlice
+	err = yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal profile metadata: %w", err)
+	}
+
+	updated, err := yaml.Marshal(update(doc))
+	if err != nil {
+		return fmt.Errorf("failed to marshal profile metadata: %w", err)
+	}
+
+	tempPath := path + ".tmp"
+	err = os.WriteFile(tempPath, updated, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write profile metadata: %w", err)
+	}
+
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to replace profile metadata: %w", err)
+	}
+
+	return nil
+}
+
+// setDetailsValue sets a value in the profile section of a raw profile.yaml document.
+func setDetailsValue(doc yaml.MapSlice, key string, value any) yaml.MapSlice {
+	details, _ := lo.Find(doc, func(item yaml.MapItem) bool {
+		return item.Key == "profile"
+	})
+
+	section, _ := details.Value.(yaml.MapSlice)
+	section = setDocumentValue(section, key, value)
+
+	return setDocumentValue(doc, "profile", section)
+}
+
+// copyProfileDir copies the files of a profile directory to a new directory.
+func copyProfileDir(src string, dst string) error {
+	return copyProfileFS(os.DirFS(src), dst)
+}
+
+// copyProfileFS copies the files of a file system rooted at a profile directory to a new directory.
+func copyProfileFS(src fs.FS, dst string) error {
+	return fs.WalkDir(src, ".", func(relPath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dst, filepath.FromSlash(relPath))
+
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		in, err := src.Open(relPath)
+		if err != nil {
+			return fmt.Errorf("failed to open file: %w", err)
+		}
+		defer in.Close()
+
+		out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to create file: %w", err)
+		}
+
+		_, err = io.Copy(out, in)
+		if err != nil {
+			out.Close()
+			return fmt.Errorf("failed to copy file: %w", err)
+		}
+
+		err = out.Close()
+		if err != nil {
+			return fmt.Errorf("failed to write file: %w", err)
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,246 @@
+package profile
+
+import (
+	"archive/zip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	// ErrArchiveTooLarge is returned when the total uncompressed size of a profile archive exceeds the limit.
+	ErrArchiveTooLarge = errors.New("profile archive is too large")
+	// ErrArchiveFileTooLarge is returned when a file in a profile archive exceeds the per-file size limit.
+	ErrArchiveFileTooLarge = errors.New("file in profile archive is too large")
+	// ErrArchiveTooManyEntries is returned when a profile archive contains too many entries.
+	ErrArchiveTooManyEntries = errors.New("profile archive contains too many entries")
+	// ErrArchiveCompressionRatio is returned when a file in a profile archive is compressed suspiciously well.
+	ErrArchiveCompressionRatio = errors.New("file in profile archive exceeds the compression ratio limit")
+	// ErrArchiveFileTypeNotAllowed is returned when a profile archive contains a file that is not allowed in
+	// profiles.
+	ErrArchiveFileTypeNotAllowed = errors.New("file type is not allowed in profile archives")
+	// ErrArchiveInvalidPath is returned when an entry in a profile archive would be extracted outside of the
+	// profile directory.
+	ErrArchiveInvalidPath = errors.New("invalid path in profile archive")
+	// ErrArchiveUnsupportedEntry is returned when a profile archive contains a symlink or other non-regular
+	// file.
+	ErrArchiveUnsupportedEntry = errors.New("unsupported entry in profile archive")
+)
+
+// ImportError is returned when an entry in a profile archive violates the import limits or restrictions. It
+// wraps one of the ErrArchive* errors.
+type ImportError struct {
+	// The name of the offending entry in the archive, or empty if the archive as a whole was rejected.
+	Entry string
+	// The reason the entry was rejected.
+	Err error
+}
+
+func (e *ImportError) Error() string {
+	if e.Entry == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %v", e.Entry, e.Err)
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// ImportLimits are the limits enforced when extracting profile archives. A zero value disables the
+// corresponding limit.
+type ImportLimits struct {
+	// The maximum total uncompressed size of all files in the archive, in bytes.
+	MaxTotalSize int64
+	// The maximum uncompressed size of a single file in the archive, in bytes.
+	MaxFileSize int64
+	// The maximum number of entries in the archive, including directories.
+	MaxEntries int
+	// The maximum ratio between the uncompressed and compressed size of a file. Only files larger than
+	// compressionRatioMinSize are checked, since small files can legitimately compress very well.
+	MaxCompressionRatio float64
+}
+
+// DefaultImportLimits are the import limits used unless changed with SetImportLimits.
+var DefaultImportLimits = ImportLimits{
+	MaxTotalSize:        512 << 20,
+	MaxFileSize:         64 << 20,
+	MaxEntries:          4096,
+	MaxCompressionRatio: 200,
+}
+
+// compressionRatioMinSize is the size above which the compression ratio of a file is checked.
+const compressionRatioMinSize = 1 << 20
+
+var (
+	importLimits     = DefaultImportLimits
+	importLimitsLock sync.RWMutex
+)
+
+// SetImportLimits sets the limits enforced when importing profile archives.
+func SetImportLimits(limits ImportLimits) {
+	importLimitsLock.Lock()
+	defer importLimitsLock.Unlock()
+	importLimits = limits
+}
+
+// GetImportLimits returns the limits enforced when importing profile archives.
+func GetImportLimits() ImportLimits {
+	importLimitsLock.RLock()
+	defer importLimitsLock.RUnlock()
+	return importLimits
+}
+
+// allowedImportExtensions are the file extensions that may be extracted from profile archives.
+var allowedImportExtensions = []string{
+	// Audio
+	".wav", ".mp3",
+	// Profile definitions
+	".yaml", ".yml",
+	// Images
+	".png", ".jpg", ".jpeg", ".gif", ".webp",
+}
+
+// allowedImportFileNames are the file names that may be extracted from profile archives regardless of their
+// extension. Bundled profiles ship a LICENSE file, and exported archives include a manifest.
+var allowedImportFileNames = []string{
+	ManifestFileName,
+	"LICENSE", "LICENSE.txt", "LICENSE.md",
+	"README", "README.txt", "README.md",
+}
+
+// isAllowedImportFile returns true if the file may be extracted from a profile archive.
+func isAllowedImportFile(name string) bool {
+	base := path.Base(name)
+	for _, allowed := range allowedImportFileNames {
+		if strings.EqualFold(base, allowed) {
+			return true
+		}
+	}
+
+	ext := path.Ext(base)
+	for _, allowed := range allowedImportExtensions {
+		if strings.EqualFold(ext, allowed) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// extractArchive extracts a profile archive into dir, enforcing the given limits. Files are created with
+// mode 0644 and directories with mode 0755, regardless of the modes recorded in the archive.
+func extractArchive(archive *zip.Reader, dir string, limits ImportLimits) error {
+	if limits.MaxEntries > 0 && len(archive.File) > limits.MaxEntries {
+		return &ImportError{
+			Err: fmt.Errorf("%w: %d entries, limit is %d", ErrArchiveTooManyEntries, len(archive.File), limits.MaxEntries),
+		}
+	}
+
+	absTargetDir, err := filepath.Abs(dir)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path of target directory: %w", err)
+	}
+
+	var totalSize int64
+	for _, file := range archive.File {
+		// Check for ZipSlip vulnerability by ensuring the resolved path is within the target directory
+		filePath := filepath.Join(absTargetDir, file.Name)
+		relPath, err := filepath.Rel(absTargetDir, filePath)
+		if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+			return &ImportError{Entry: file.Name, Err: ErrArchiveInvalidPath}
+		}
+
+		mode := file.Mode()
+		if mode.IsDir() {
+			err = os.MkdirAll(filePath, 0755)
+			if err != nil {
+				return fmt.Errorf("failed to create directory: %w", err)
+			}
+			continue
+		}
+
+		if !mode.IsRegular() {
+			return &ImportError{Entry: file.Name, Err: ErrArchiveUnsupportedEntry}
+		}
+
+		if !isAllowedImportFile(file.Name) {
+			return &ImportError{Entry: file.Name, Err: ErrArchiveFileTypeNotAllowed}
+		}
+
+		// Reject entries whose declared size is already over the limits. The declared size can't be trusted,
+		// so the limits are enforced again while extracting.
+		if limits.MaxFileSize > 0 && file.UncompressedSize64 > uint64(limits.MaxFileSize) {
+			return &ImportError{Entry: file.Name, Err: ErrArchiveFileTooLarge}
+		}
+
+		// Ensure parent directory exists
+		err = os.MkdirAll(filepath.Dir(filePath), 0755)
+		if err != nil {
+			return fmt.Errorf("failed to create parent directory: %w", err)
+		}
+
+		written, err := extractArchiveFile(file, filePath, limits, totalSize)
+		if err != nil {
+			return err
+		}
+		totalSize += written
+	}
+
+	return nil
+}
+
+// extractArchiveFile extracts a single file from a profile archive and returns the number of bytes written.
+// extracted is the number of bytes extracted from the archive so far.
+func extractArchiveFile(file *zip.File, filePath string, limits ImportLimits, extracted int64) (int64, error) {
+	fileReader, err := file.Open()
+	if err != nil {
+		return 0, fmt.Errorf("failed to open file in zip: %w", err)
+	}
+	defer fileReader.Close()
+
+	outFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create file: %w", err)
+	}
+	defer outFile.Close()
+
+	// Read one byte past the smallest applicable limit so that exceeding it can be detected.
+	limit := int64(-1)
+	limitErr := ErrArchiveFileTooLarge
+	if limits.MaxFileSize > 0 {
+		limit = limits.MaxFileSize
+	}
+	if limits.MaxTotalSize > 0 && (limit < 0 || limits.MaxTotalSize-extracted < limit) {
+		limit = limits.MaxTotalSize - extracted
+		limitErr = ErrArchiveTooLarge
+	}
+
+	var reader io.Reader = fileReader
+	if limit >= 0 {
+		reader = io.LimitReader(fileReader, limit+1)
+	}
+
+	written, err := io.Copy(outFile, reader)
+	if err != nil {
+		return 0, fmt.Errorf("failed to extract file: %w", err)
+	}
+
+	if limit >= 0 && written > limit {
+		return 0, &ImportError{Entry: file.Name, Err: limitErr}
+	}
+
+	if limits.MaxCompressionRatio > 0 && written > compressionRatioMinSize {
+		if file.CompressedSize64 == 0 ||
+			float64(written)/float64(file.CompressedSize64) > limits.MaxCompressionRatio {
+			return 0, &ImportError{Entry: file.Name, Err: ErrArchiveCompressionRatio}
+		}
+	}
+
+	return written, nil
+}
@@ -0,0 +1,250 @@
+package profile
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testArchiveEntry is an entry of a zip archive built by buildTestArchive.
+type testArchiveEntry struct {
+	name    string
+	content []byte
+	// The mode of the entry. Regular files are used if zero.
+	mode os.FileMode
+	// Whether the entry is stored instead of deflated.
+	store bool
+	// The uncompressed size recorded for the entry instead of its real size, if not zero. The entry is written
+	// raw and stored.
+	declaredSize uint64
+}
+
+// buildTestArchive builds a zip archive with the given entries and opens it for reading.
+func buildTestArchive(t *testing.T, entries ...testArchiveEntry) *zip.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for _, entry := range entries {
+		header := &zip.FileHeader{Name: entry.name, Method: zip.Deflate}
+		if entry.store || entry.declaredSize != 0 {
+			header.Method = zip.Store
+		}
+		if entry.mode != 0 {
+			header.SetMode(entry.mode)
+		}
+
+		if entry.declaredSize != 0 {
+			header.CRC32 = crc32.ChecksumIEEE(entry.content)
+			header.CompressedSize64 = uint64(len(entry.content))
+			header.UncompressedSize64 = entry.declaredSize
+			fw, err := w.CreateRaw(header)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := fw.Write(entry.content); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+
+		fw, err := w.CreateHeader(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := fw.Write(entry.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestIsAllowedImportFile(t *testing.T) {
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"profile.yaml", true},
+		{"profile.yml", true},
+		{"audio/press.wav", true},
+		{"audio/PRESS.MP3", true},
+		{"preview.png", true},
+		{"LICENSE", true},
+		{"docs/readme.md", true},
+		{ManifestFileName, true},
+		{"install.sh", false},
+		{"audio/press.ogg", false},
+		{"audio/press.flac", false},
+		{"audio/press.wav.exe", false},
+		{"Makefile", false},
+		{".wav/payload", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isAllowedImportFile(tt.name); got != tt.want {
+				t.Errorf("isAllowedImportFile(%q) = %v, want %v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractArchive(t *testing.T) {
+	small := []byte("RIFF")
+	zeros := make([]byte, 2*compressionRatioMinSize)
+	chunk := bytes.Repeat([]byte("0123456789abcdef"), 400<<10/16)
+
+	tests := []struct {
+		name    string
+		entries []testArchiveEntry
+		limits  ImportLimits
+		wantErr error
+		// The entry reported by the ImportError, if wantErr is one of the ErrArchive* errors.
+		wantEntry string
+	}{
+		{
+			name: "valid archive",
+			entries: []testArchiveEntry{
+				{name: "profile.yaml", content: []byte("profile:\n  name: Test\n")},
+				{name: "audio/", mode: os.ModeDir | 0755},
+				{name: "audio/press.wav", content: small},
+			},
+			limits: DefaultImportLimits,
+		},
+		{
+			name:      "parent directory",
+			entries:   []testArchiveEntry{{name: "../evil.wav", content: small}},
+			limits:    DefaultImportLimits,
+			wantErr:   ErrArchiveInvalidPath,
+			wantEntry: "../evil.wav",
+		},
+		{
+			name:      "nested parent directory",
+			entries:   []testArchiveEntry{{name: "audio/../../evil.wav", content: small}},
+			limits:    DefaultImportLimits,
+			wantErr:   ErrArchiveInvalidPath,
+			wantEntry: "audio/../../evil.wav",
+		},
+		{
+			name:      "symlink",
+			entries:   []testArchiveEntry{{name: "audio/press.wav", content: []byte("/etc/passwd"), mode: os.ModeSymlink | 0777}},
+			limits:    DefaultImportLimits,
+			wantErr:   ErrArchiveUnsupportedEntry,
+			wantEntry: "audio/press.wav",
+		},
+		{
+			name:      "disallowed extension",
+			entries:   []testArchiveEntry{{name: "install.sh", content: []byte("#!/bin/sh\n")}},
+			limits:    DefaultImportLimits,
+			wantErr:   ErrArchiveFileTypeNotAllowed,
+			wantEntry: "install.sh",
+		},
+		{
+			name:    "too many entries",
+			entries: []testArchiveEntry{{name: "a.wav", content: small}, {name: "b.wav", content: small}},
+			limits:  ImportLimits{MaxEntries: 1},
+			wantErr: ErrArchiveTooManyEntries,
+		},
+		{
+			name:      "declared size over file limit",
+			entries:   []testArchiveEntry{{name: "big.wav", content: small, declaredSize: 1 << 30}},
+			limits:    ImportLimits{MaxFileSize: 1 << 20},
+			wantErr:   ErrArchiveFileTooLarge,
+			wantEntry: "big.wav",
+		},
+		{
+			// The entry declares a size under the limits but contains more data. The zip reader rejects the
+			// entry once it reads past the declared size.
+			name:    "declared size smaller than content",
+			entries: []testArchiveEntry{{name: "lying.wav", content: chunk, declaredSize: 16}},
+			limits:  ImportLimits{MaxFileSize: 1 << 20},
+			wantErr: zip.ErrFormat,
+		},
+		{
+			name:      "file over file limit",
+			entries:   []testArchiveEntry{{name: "big.wav", content: chunk, store: true}},
+			limits:    ImportLimits{MaxFileSize: 100 << 10},
+			wantErr:   ErrArchiveFileTooLarge,
+			wantEntry: "big.wav",
+		},
+		{
+			name: "total size crossed partway",
+			entries: []testArchiveEntry{
+				{name: "a.wav", content: chunk, store: true},
+				{name: "b.wav", content: chunk, store: true},
+				{name: "c.wav", content: chunk, store: true},
+			},
+			limits:    ImportLimits{MaxTotalSize: 1 << 20},
+			wantErr:   ErrArchiveTooLarge,
+			wantEntry: "c.wav",
+		},
+		{
+			name:      "compression ratio",
+			entries:   []testArchiveEntry{{name: "zeros.wav", content: zeros}},
+			limits:    ImportLimits{MaxCompressionRatio: 200},
+			wantErr:   ErrArchiveCompressionRatio,
+			wantEntry: "zeros.wav",
+		},
+		{
+			name:    "compression ratio disabled",
+			entries: []testArchiveEntry{{name: "zeros.wav", content: zeros}},
+			limits:  ImportLimits{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "profile")
+			if err := os.Mkdir(dir, 0755); err != nil {
+				t.Fatal(err)
+			}
+
+			err := extractArchive(buildTestArchive(t, tt.entries...), dir, tt.limits)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("extractArchive() error = %v", err)
+				}
+				for _, entry := range tt.entries {
+					if strings.HasSuffix(entry.name, "/") {
+						continue
+					}
+					data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(entry.name)))
+					if err != nil {
+						t.Fatalf("failed to read extracted file: %v", err)
+					}
+					if !bytes.Equal(data, entry.content) {
+						t.Errorf("extracted %s does not match the archive", entry.name)
+					}
+				}
+				return
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("extractArchive() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var importErr *ImportError
+			if tt.wantEntry != "" && (!errors.As(err, &importErr) || importErr.Entry != tt.wantEntry) {
+				t.Errorf("extractArchive() error = %v, want an ImportError for %s", err, tt.wantEntry)
+			}
+
+			if _, err := os.Stat(filepath.Join(parent, "evil.wav")); !os.IsNotExist(err) {
+				t.Errorf("file was extracted outside of the target directory")
+			}
+		})
+	}
+}
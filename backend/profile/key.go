@@ -1,19 +1,92 @@
 package profile
 
+import (
+	"fmt"
+	"strings"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/key"
+	"github.com/samber/lo"
+)
+
 // Key represents a key definition in the Other section of a profile.
 type Key struct {
-	// The source to play for events corresponding to the keys listed in the Keys field.
-	// This should correspond to the source ID in the profile sources.
-	Sound any `yaml:"sound"`
-	// The keys that trigger this sound source.
+	// The sources to play for events corresponding to the keys listed in the Keys field.
+	// These should correspond to source IDs in the profile sources.
+	Sound SoundRef `yaml:"sound"`
+	// The keys that trigger this sound source. Keys are listed by name or code, or selected with a group
+	// such as "@letters" or a region such as "@row:2" or "@column:1-5". When WhenModifiers is set, the keys
+	// may be omitted to match every key.
 	Keys *[]string `yaml:"keys,omitempty"`
+	// The modifiers that must be held for this entry to apply, such as "shift" or "ctrl". Entries with
+	// modifiers take precedence over entries without them, and entries requiring more modifiers take
+	// precedence over entries requiring fewer.
+	WhenModifiers []string `yaml:"when_modifiers,omitempty"`
+}
+
+// KeyMatch describes how specifically a key entry matches a key. Higher values are more specific.
+type KeyMatch int
+
+const (
+	// KeyMatchNone means that the entry does not apply to the key.
+	KeyMatchNone KeyMatch = iota
+	// KeyMatchAll means that the entry does not list any keys and applies to every key.
+	KeyMatchAll
+	// KeyMatchSelector means that the key is selected by a group or region selector such as "@letters".
+	KeyMatchSelector
+	// KeyMatchExact means that the key is listed by name or code.
+	KeyMatchExact
+)
+
+// MatchKey returns how specifically the entry matches the given key. Key names listed in the entry match
+// more specifically than selectors, so that an entry listing a key overrides an entry selecting its group.
+func (k Key) MatchKey(pressed key.Key) KeyMatch {
+	if k.Keys == nil || len(*k.Keys) == 0 {
+		return lo.Ternary(len(k.WhenModifiers) > 0, KeyMatchAll, KeyMatchNone)
+	}
+
+	match := KeyMatchNone
+	for _, name := range *k.Keys {
+		if key.IsSelector(name) {
+			selector, err := key.ParseSelector(name)
+			if err == nil && selector.Matches(pressed) {
+				match = KeyMatchSelector
+			}
+			continue
+		}
+
+		if strings.EqualFold(name, pressed.Name) || strings.EqualFold(name, fmt.Sprintf("%d", pressed.Code)) {
+			return KeyMatchExact
+		}
+	}
+
+	return match
+}
+
+// MatchesKey returns true if the entry applies to the given key.
+func (k Key) MatchesKey(pressed key.Key) bool {
+	return k.MatchKey(pressed) != KeyMatchNone
+}
+
+// MatchesModifiers returns true if every modifier in WhenModifiers is held. Entries without modifiers always
+// match.
+func (k Key) MatchesModifiers(held []key.Key) bool {
+	return lo.EveryBy(k.WhenModifiers, func(name string) bool {
+		keys, ok := key.FindModifier(name)
+		if !ok {
+			return false
+		}
+
+		return lo.ContainsBy(keys, func(modifier key.Key) bool {
+			return lo.ContainsBy(held, modifier.Equals)
+		})
+	})
 }
 
 // Keys represents a list of keys in a profile.
 type Keys struct {
-	// The default source to use for any key that is not defined in the Other section.
-	// This should correspond to the source ID in the profile sources.
-	Default []string `yaml:"default"`
+	// The default sources to use for any key that is not defined in the Other section, one of which is
+	// picked at random for each key event. These should correspond to source IDs in the profile sources.
+	Default SoundRef `yaml:"default"`
 	// The other keys that trigger a specific sound source.
 	Other []Key `yaml:"other"`
 }
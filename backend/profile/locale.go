@@ -0,0 +1,142 @@
+package profile
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// NormalizeLocale returns a locale in its canonical form, such as "pt-BR" for "pt_br" or "de" for "DE". The
+// encoding and modifier of POSIX locales, such as ".UTF-8" in "de_DE.UTF-8", are removed. It returns an empty
+// string if the locale is not valid.
+func NormalizeLocale(locale string) string {
+	locale = strings.TrimSpace(locale)
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "" || locale == "C" || locale == "POSIX" {
+		return ""
+	}
+
+	parts := strings.Split(strings.ReplaceAll(locale, "_", "-"), "-")
+	for i, part := range parts {
+		if part == "" || !isASCIILetterOrDigit(part) {
+			return ""
+		}
+
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		default:
+			parts[i] = strings.ToLower(part)
+		}
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// SystemLocale returns the locale of the user from the LC_ALL, LC_MESSAGES and LANG environment variables, or an
+// empty string if none is set.
+func SystemLocale() string {
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if locale := NormalizeLocale(os.Getenv(env)); locale != "" {
+			return locale
+		}
+	}
+
+	return ""
+}
+
+// LocalizedName returns the name of the profile translated to the locale, or the canonical name if there is no
+// translation. See Localize for how translations are chosen.
+func (p *Profile) LocalizedName(locale string) string {
+	return Localize(p.Details.Name, p.Details.NameI18n, locale)
+}
+
+// LocalizedDescription returns the description of the profile translated to the locale, or the canonical
+// description if there is no translation. See Localize for how translations are chosen.
+func (p *Profile) LocalizedDescription(locale string) string {
+	return Localize(p.Details.Description, p.Details.DescriptionI18n, locale)
+}
+
+// Localize returns the translation for the locale, falling back to less specific locales, so that "de-AT" uses
+// the "de" translation if there is no "de-AT" translation. If there is no translation, or the locale is empty,
+// the canonical value is returned.
+func Localize(canonical string, translations map[string]string, locale string) string {
+	locale = NormalizeLocale(locale)
+	if locale == "" || len(translations) == 0 {
+		return canonical
+	}
+
+	byLocale := make(map[string]string, len(translations))
+	for l, value := range translations {
+		if value = strings.TrimSpace(value); value != "" {
+			byLocale[NormalizeLocale(l)] = value
+		}
+	}
+
+	for locale != "" {
+		if value, ok := byLocale[locale]; ok {
+			return value
+		}
+
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+
+	return canonical
+}
+
+// ListLocales returns every locale that the names or descriptions of the loaded profiles are translated to,
+// sorted and without duplicates.
+func ListLocales() []string {
+	seen := make(map[string]bool)
+	for _, p := range defaultStore.Profiles() {
+		for _, translations := range []map[string]string{p.Details.NameI18n, p.Details.DescriptionI18n} {
+			for locale := range translations {
+				if locale = NormalizeLocale(locale); locale != "" {
+					seen[locale] = true
+				}
+			}
+		}
+	}
+
+	locales := lo.Keys(seen)
+	sort.Strings(locales)
+
+	return locales
+}
+
+// withTranslations returns the canonical value followed by its translations, separated by newlines, so that
+// they can be searched together.
+func withTranslations(canonical string, translations map[string]string) string {
+	if len(translations) == 0 {
+		return canonical
+	}
+
+	values := []string{canonical}
+	for _, value := range translations {
+		values = append(values, value)
+	}
+
+	return strings.Join(values, "\n")
+}
+
+func isASCIILetterOrDigit(s string) bool {
+	for _, r := range s {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,301 @@
+package profile
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ManifestFileName is the name of the manifest file at the root of exported profile archives.
+const ManifestFileName = "manifest.json"
+
+// manifestVersion is the version of the manifest format written by ExportProfile.
+const manifestVersion = 1
+
+var (
+	// ErrManifestMismatch is returned when the files in a profile archive do not match its manifest.
+	ErrManifestMismatch = errors.New("profile archive does not match its manifest")
+	// ErrInvalidSignature is returned when the signature of a profile archive manifest is invalid.
+	ErrInvalidSignature = errors.New("invalid profile archive signature")
+)
+
+// VerificationState represents how much of a profile archive could be verified on import.
+type VerificationState string
+
+const (
+	// VerificationNone is used for archives without a manifest, such as archives exported by older versions.
+	VerificationNone VerificationState = "none"
+	// VerificationHashes is used for archives whose files match the hashes in their unsigned manifest.
+	VerificationHashes VerificationState = "hashes"
+	// VerificationSelfSigned is used for archives whose files match the hashes in their manifest, and whose
+	// manifest carries a valid signature by a key that is not trusted. The key is taken from the archive itself,
+	// so the signature only shows that the archive is unmodified since whoever holds the key signed it.
+	VerificationSelfSigned VerificationState = "self-signed"
+	// VerificationTrusted is used for archives whose files match the hashes in their manifest, and whose
+	// manifest carries a valid signature by a key with a trusted fingerprint.
+	VerificationTrusted VerificationState = "trusted"
+)
+
+var (
+	trustedFingerprints     []string
+	trustedFingerprintsLock sync.RWMutex
+)
+
+// SetTrustedFingerprints sets the fingerprints, as returned by KeyFingerprint, of the signing keys whose
+// signatures are trusted when importing profile archives.
+func SetTrustedFingerprints(fingerprints []string) {
+	trustedFingerprintsLock.Lock()
+	defer trustedFingerprintsLock.Unlock()
+	trustedFingerprints = slices.Clone(fingerprints)
+}
+
+// GetTrustedFingerprints returns the fingerprints of the signing keys whose signatures are trusted when
+// importing profile archives.
+func GetTrustedFingerprints() []string {
+	trustedFingerprintsLock.RLock()
+	defer trustedFingerprintsLock.RUnlock()
+	return slices.Clone(trustedFingerprints)
+}
+
+// isTrustedFingerprint returns true if a key fingerprint is one of the trusted fingerprints.
+func isTrustedFingerprint(fingerprint string) bool {
+	trustedFingerprintsLock.RLock()
+	defer trustedFingerprintsLock.RUnlock()
+	return slices.ContainsFunc(trustedFingerprints, func(f string) bool {
+		return strings.EqualFold(strings.TrimSpace(f), fingerprint)
+	})
+}
+
+// Manifest describes the contents of an exported profile archive.
+type Manifest struct {
+	// The version of the manifest format.
+	Version int `json:"version"`
+	// The name of the exported profile.
+	Profile string `json:"profile"`
+	// The hex encoded SHA-256 hash of every file in the archive, keyed by slash separated path.
+	Files map[string]string `json:"files"`
+	// The signature of the manifest, if the archive was signed.
+	Signature *ManifestSignature `json:"signature,omitempty"`
+}
+
+// ManifestSignature represents the ed25519 signature of a manifest.
+type ManifestSignature struct {
+	// The name of the signer, as provided when exporting.
+	Signer string `json:"signer"`
+	// The base64 encoded ed25519 public key of the signer.
+	PublicKey string `json:"public_key"`
+	// The base64 encoded ed25519 signature of the manifest.
+	Signature string `json:"signature"`
+}
+
+// signedPayload returns the bytes covered by the manifest signature. The signer and public key are part of
+// the payload so that they cannot be replaced without invalidating the signature.
+func (m *Manifest) signedPayload(signer string, publicKey string) ([]byte, error) {
+	return json.Marshal(struct {
+		Version   int               `json:"version"`
+		Profile   string            `json:"profile"`
+		Files     map[string]string `json:"files"`
+		Signer    string            `json:"signer"`
+		PublicKey string            `json:"public_key"`
+	}{m.Version, m.Profile, m.Files, signer, publicKey})
+}
+
+// sign signs the manifest with the given private key.
+func (m *Manifest) sign(signer string, key ed25519.PrivateKey) error {
+	publicKey := base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey))
+
+	payload, err := m.signedPayload(signer, publicKey)
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	m.Signature = &ManifestSignature{
+		Signer:    signer,
+		PublicKey: publicKey,
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload)),
+	}
+
+	return nil
+}
+
+// verifySignature verifies the signature of the manifest and returns the public key of the signer.
+func (m *Manifest) verifySignature() (ed25519.PublicKey, error) {
+	publicKey, err := base64.StdEncoding.DecodeString(m.Signature.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w: malformed public key", ErrInvalidSignature)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(m.Signature.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed signature", ErrInvalidSignature)
+	}
+
+	payload, err := m.signedPayload(m.Signature.Signer, m.Signature.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return nil, ErrInvalidSignature
+	}
+
+	return publicKey, nil
+}
+
+// KeyFingerprint returns a short, human readable fingerprint of an ed25519 public key.
+func KeyFingerprint(publicKey ed25519.PublicKey) string {
+	sum := sha256.Sum256(publicKey)
+	encoded := hex.EncodeToString(sum[:8])
+
+	parts := make([]string, 0, len(encoded)/4)
+	for i := 0; i < len(encoded); i += 4 {
+		parts = append(parts, encoded[i:i+4])
+	}
+
+	return strings.Join(parts, ":")
+}
+
+// ImportResult describes a profile that was imported and how its archive was verified.
+type ImportResult struct {
+	// The name of the imported profile.
+	Profile string `json:"profile"`
+	// How much of the archive could be verified.
+	Verification VerificationState `json:"verification"`
+	// The name of the signer, for signed archives. The name is chosen by the signer and is only meaningful for
+	// trusted archives.
+	Signer string `json:"signer,omitempty"`
+	// The fingerprint of the signer's public key, for signed archives.
+	SignerFingerprint string `json:"signerFingerprint,omitempty"`
+	// The number of shared samples in the archive that were added to the sample library.
+	SharedSamplesAdded int `json:"sharedSamplesAdded"`
+	// The number of shared samples in the archive that the sample library already had.
+	SharedSamplesDeduplicated int `json:"sharedSamplesDeduplicated"`
+}
+
+// verifyManifest verifies the files extracted from a profile archive into dir against the manifest in the
+// archive, if there is one. The manifest is removed from dir once it has been verified.
+func verifyManifest(dir string) (ImportResult, error) {
+	manifestPath := filepath.Join(dir, ManifestFileName)
+	manifestData, err := os.ReadFile(manifestPath)
+	if os.IsNotExist(err) {
+		return ImportResult{Verification: VerificationNone}, nil
+	}
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var manifest Manifest
+	err = json.Unmarshal(manifestData, &manifest)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to parse manifest: %w", err)
+	}
+
+	files, err := hashProfileFiles(dir)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	for path, hash := range manifest.Files {
+		actual, ok := files[path]
+		if !ok {
+			return ImportResult{}, fmt.Errorf("%w: %s is missing", ErrManifestMismatch, path)
+		}
+		if !strings.EqualFold(actual, hash) {
+			return ImportResult{}, fmt.Errorf("%w: %s has been modified", ErrManifestMismatch, path)
+		}
+	}
+	for path := range files {
+		if _, ok := manifest.Files[path]; !ok {
+			return ImportResult{}, fmt.Errorf("%w: %s is not listed", ErrManifestMismatch, path)
+		}
+	}
+
+	result := ImportResult{Verification: VerificationHashes}
+	if manifest.Signature != nil {
+		publicKey, err := manifest.verifySignature()
+		if err != nil {
+			return ImportResult{}, err
+		}
+
+		result.Signer = manifest.Signature.Signer
+		result.SignerFingerprint = KeyFingerprint(publicKey)
+		result.Verification = VerificationSelfSigned
+		if isTrustedFingerprint(result.SignerFingerprint) {
+			result.Verification = VerificationTrusted
+		}
+	}
+
+	err = os.Remove(manifestPath)
+	if err != nil {
+		return ImportResult{}, fmt.Errorf("failed to remove manifest: %w", err)
+	}
+
+	return result, nil
+}
+
+// hashProfileFiles returns the hex encoded SHA-256 hash of every file in a profile directory, keyed by slash
+// separated path relative to the directory. The manifest file is not included.
+func hashProfileFiles(dir string) (map[string]string, error) {
+	files := make(map[string]string)
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path: %w", err)
+		}
+		relPath = filepath.ToSlash(relPath)
+
+		if relPath == ManifestFileName {
+			return nil
+		}
+
+		hash, err := hashFile(path)
+		if err != nil {
+			return err
+		}
+
+		files[relPath] = hash
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash profile files: %w", err)
+	}
+
+	return files, nil
+}
+
+// hashFile returns the hex encoded SHA-256 hash of a file.
+func hashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file: %w", err)
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	_, err = io.Copy(hash, file)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash file: %w", err)
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
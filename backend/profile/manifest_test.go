@@ -0,0 +1,197 @@
+package profile
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeArchiveDir writes the files of an extracted profile archive to a temporary directory, with a manifest of
+// the files signed by key if key is not nil. The modify function is called after the manifest is written, to
+// tamper with the directory or manifest.
+func writeArchiveDir(t *testing.T, key ed25519.PrivateKey, modify func(dir string, manifest *Manifest)) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"profile.yaml":    "profile:\n  name: Signed\n",
+		"audio/press.wav": "press",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	hashes, err := hashProfileFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	manifest := Manifest{Version: manifestVersion, Profile: "Signed", Files: hashes}
+	if key != nil {
+		if err := manifest.sign("Alex", key); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if modify != nil {
+		modify(dir, &manifest)
+	}
+
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ManifestFileName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestVerifyManifest(t *testing.T) {
+	publicKey, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := KeyFingerprint(publicKey)
+
+	tests := []struct {
+		name             string
+		key              ed25519.PrivateKey
+		trusted          []string
+		modify           func(dir string, manifest *Manifest)
+		wantVerification VerificationState
+		wantErr          error
+	}{
+		{
+			name:             "unsigned",
+			wantVerification: VerificationHashes,
+		},
+		{
+			name:             "self-signed",
+			key:              key,
+			wantVerification: VerificationSelfSigned,
+		},
+		{
+			name:             "trusted",
+			key:              key,
+			trusted:          []string{fingerprint},
+			wantVerification: VerificationTrusted,
+		},
+		{
+			name:             "signed by another trusted key",
+			key:              otherKey,
+			trusted:          []string{fingerprint},
+			wantVerification: VerificationSelfSigned,
+		},
+		{
+			name: "tampered file",
+			key:  key,
+			modify: func(dir string, _ *Manifest) {
+				os.WriteFile(filepath.Join(dir, "audio", "press.wav"), []byte("tampered"), 0644)
+			},
+			wantErr: ErrManifestMismatch,
+		},
+		{
+			name: "missing file",
+			key:  key,
+			modify: func(dir string, _ *Manifest) {
+				os.Remove(filepath.Join(dir, "audio", "press.wav"))
+			},
+			wantErr: ErrManifestMismatch,
+		},
+		{
+			name: "unlisted file",
+			key:  key,
+			modify: func(dir string, _ *Manifest) {
+				os.WriteFile(filepath.Join(dir, "audio", "extra.wav"), []byte("extra"), 0644)
+			},
+			wantErr: ErrManifestMismatch,
+		},
+		{
+			name:    "bad signature",
+			key:     key,
+			trusted: []string{fingerprint},
+			modify: func(_ string, manifest *Manifest) {
+				manifest.Profile = "Renamed"
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "replaced signer",
+			key:     key,
+			trusted: []string{fingerprint},
+			modify: func(_ string, manifest *Manifest) {
+				manifest.Signature.Signer = "Someone else"
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "replaced public key",
+			key:  otherKey,
+			modify: func(_ string, manifest *Manifest) {
+				manifest.Signature.PublicKey = base64.StdEncoding.EncodeToString(publicKey)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetTrustedFingerprints(tt.trusted)
+			t.Cleanup(func() { SetTrustedFingerprints(nil) })
+
+			dir := writeArchiveDir(t, tt.key, tt.modify)
+
+			result, err := verifyManifest(dir)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("verifyManifest() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyManifest() error = %v", err)
+			}
+
+			if result.Verification != tt.wantVerification {
+				t.Errorf("verification = %q, want %q", result.Verification, tt.wantVerification)
+			}
+			if tt.key != nil {
+				wantFingerprint := KeyFingerprint(tt.key.Public().(ed25519.PublicKey))
+				if result.SignerFingerprint != wantFingerprint {
+					t.Errorf("signer fingerprint = %q, want %q", result.SignerFingerprint, wantFingerprint)
+				}
+			}
+			if _, err := os.Stat(filepath.Join(dir, ManifestFileName)); !os.IsNotExist(err) {
+				t.Errorf("manifest was not removed after verification")
+			}
+		})
+	}
+}
+
+func TestVerifyManifestWithoutManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	result, err := verifyManifest(dir)
+	if err != nil {
+		t.Fatalf("verifyManifest() error = %v", err)
+	}
+	if result.Verification != VerificationNone {
+		t.Errorf("verification = %q, want %q", result.Verification, VerificationNone)
+	}
+}
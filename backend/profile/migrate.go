@@ -0,0 +1,152 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v2"
+)
+
+// CurrentSchemaVersion is the newest profile.yaml schema version supported by this package. Profiles using an
+// older schema version are migrated to this version when they are loaded.
+const CurrentSchemaVersion = 2
+
+// ErrUnsupportedSchemaVersion is returned when a profile uses a schema version that is newer than
+// CurrentSchemaVersion.
+var ErrUnsupportedSchemaVersion = errors.New("unsupported profile schema version")
+
+// migration upgrades a raw profile.yaml document by a single schema version.
+type migration func(doc yaml.MapSlice) (yaml.MapSlice, error)
+
+// migrations contains the migrations between schema versions, where migrations[n] upgrades a document from
+// schema version n to schema version n+1. Profiles without a schema_version field are version 0.
+var migrations = []migration{
+	// 0 -> 1: Version 1 is the first versioned schema and is otherwise identical to
+	// unversioned profiles, so only the version is recorded.
+	func(doc yaml.MapSlice) (yaml.MapSlice, error) {
+		return doc, nil
+	},
+	// 1 -> 2: The default of the buttons section is a list of sources, like the default of the keys section,
+	// instead of a single source.
+	func(doc yaml.MapSlice) (yaml.MapSlice, error) {
+		buttons, ok := lo.Find(doc, func(item yaml.MapItem) bool {
+			return item.Key == "buttons"
+		})
+		section, isSection := buttons.Value.(yaml.MapSlice)
+		if !ok || !isSection {
+			return doc, nil
+		}
+
+		for i, item := range section {
+			if item.Key != "default" {
+				continue
+			}
+
+			if id, isString := item.Value.(string); isString {
+				section[i].Value = lo.Ternary(id == "", []string{}, []string{id})
+			}
+		}
+
+		return setDocumentValue(doc, "buttons", section), nil
+	},
+}
+
+var writeBackMigrations atomic.Bool
+
+// SetMigrationWriteBack sets whether profiles that are migrated to a newer schema version when loaded should be
+// written back to disk. The previous profile.yaml is kept as profile.yaml.bak. Since comments are not preserved
+// when a profile is written back, this is disabled by default.
+func SetMigrationWriteBack(enabled bool) {
+	writeBackMigrations.Store(enabled)
+}
+
+// parseProfile parses the contents of a profile.yaml file, migrating it to CurrentSchemaVersion if needed.
+// It returns the parsed profile, the migrated document and whether any migration was applied.
+func parseProfile(data []byte) (*Profile, []byte, bool, error) {
+	var doc yaml.MapSlice
+	err := yaml.Unmarshal(data, &doc)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to unmarshal profile metadata: %w", err)
+	}
+
+	doc, migrated, err := migrateDocument(doc)
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if migrated {
+		data, err = yaml.Marshal(doc)
+		if err != nil {
+			return nil, nil, false, fmt.Errorf("failed to marshal migrated profile metadata: %w", err)
+		}
+	}
+
+	var profile Profile
+	err = yaml.Unmarshal(data, &profile)
+	if err != nil {
+		return nil, nil, false, fmt.Errorf("failed to unmarshal profile metadata: %w", err)
+	}
+
+	return &profile, data, migrated, nil
+}
+
+// migrateDocument upgrades a raw profile.yaml document to CurrentSchemaVersion. It returns the upgraded
+// document and whether any migration was applied.
+func migrateDocument(doc yaml.MapSlice) (yaml.MapSlice, bool, error) {
+	version, err := documentSchemaVersion(doc)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if version > CurrentSchemaVersion {
+		return nil, false, fmt.Errorf(
+			"%w: the profile uses schema version %d but the newest supported version is %d, update the application to use this profile",
+			ErrUnsupportedSchemaVersion, version, CurrentSchemaVersion)
+	}
+
+	migrated := false
+	for ; version < CurrentSchemaVersion; version++ {
+		doc, err = migrations[version](doc)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to migrate profile from schema version %d to %d: %w", version, version+1, err)
+		}
+
+		doc = setDocumentValue(doc, "schema_version", version+1)
+		migrated = true
+	}
+
+	return doc, migrated, nil
+}
+
+// documentSchemaVersion returns the schema version of a raw profile.yaml document.
+func documentSchemaVersion(doc yaml.MapSlice) (int, error) {
+	for _, item := range doc {
+		if item.Key != "schema_version" {
+			continue
+		}
+
+		version, ok := item.Value.(int)
+		if !ok || version < 0 {
+			return 0, fmt.Errorf("%w: invalid schema_version %v", ErrUnsupportedSchemaVersion, item.Value)
+		}
+
+		return version, nil
+	}
+
+	return 0, nil
+}
+
+// setDocumentValue sets a top level value in a raw profile.yaml document. New keys are placed at the start of
+// the document.
+func setDocumentValue(doc yaml.MapSlice, key string, value any) yaml.MapSlice {
+	for i, item := range doc {
+		if item.Key == key {
+			doc[i].Value = value
+			return doc
+		}
+	}
+
+	return append(yaml.MapSlice{{Key: key, Value: value}}, doc...)
+}
@@ -0,0 +1,88 @@
+package profile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParseProfileMigratesButtonsDefault(t *testing.T) {
+	tests := []struct {
+		fixture      string
+		wantMigrated bool
+		wantDefault  SoundRef
+	}{
+		{fixture: "v0.yaml", wantMigrated: true, wantDefault: SoundRef{"click"}},
+		{fixture: "v1.yaml", wantMigrated: true, wantDefault: SoundRef{"click"}},
+		{fixture: "v1-no-default.yaml", wantMigrated: true, wantDefault: SoundRef{}},
+		{fixture: "v2.yaml", wantMigrated: false, wantDefault: SoundRef{"click"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", "migrate", tt.fixture))
+			if err != nil {
+				t.Fatalf("failed to read fixture: %v", err)
+			}
+
+			profile, migratedData, migrated, err := parseProfile(data)
+			if err != nil {
+				t.Fatalf("parseProfile() error = %v", err)
+			}
+			if migrated != tt.wantMigrated {
+				t.Errorf("migrated = %v, want %v", migrated, tt.wantMigrated)
+			}
+			if profile.SchemaVersion != CurrentSchemaVersion {
+				t.Errorf("schema version = %d, want %d", profile.SchemaVersion, CurrentSchemaVersion)
+			}
+			if !reflect.DeepEqual(profile.Buttons.Default, tt.wantDefault) {
+				t.Errorf("buttons.default = %#v, want %#v", profile.Buttons.Default, tt.wantDefault)
+			}
+			if len(profile.Buttons.Other) != 1 || !reflect.DeepEqual(profile.Buttons.Other[0].Buttons, &[]string{"right"}) {
+				t.Errorf("buttons.other = %+v, want the right button mapping to be kept", profile.Buttons.Other)
+			}
+
+			// The migrated document stores the default as a list, as written by the current schema.
+			var doc yaml.MapSlice
+			if err := yaml.Unmarshal(migratedData, &doc); err != nil {
+				t.Fatalf("failed to unmarshal migrated document: %v", err)
+			}
+			buttons, _ := lo.Find(doc, func(item yaml.MapItem) bool { return item.Key == "buttons" })
+			defaultItem, _ := lo.Find(buttons.Value.(yaml.MapSlice), func(item yaml.MapItem) bool { return item.Key == "default" })
+			if _, isList := defaultItem.Value.([]any); !isList {
+				t.Errorf("migrated buttons.default = %#v, want a list", defaultItem.Value)
+			}
+
+			// Parsing the migrated document again must not change it.
+			again, againData, migratedAgain, err := parseProfile(migratedData)
+			if err != nil {
+				t.Fatalf("parseProfile() of migrated document error = %v", err)
+			}
+			if migratedAgain {
+				t.Errorf("migrated document was migrated again")
+			}
+			if !bytes.Equal(againData, migratedData) {
+				t.Errorf("migrated document changed when parsed again:\n%s\nwant:\n%s", againData, migratedData)
+			}
+			if !reflect.DeepEqual(again, profile) {
+				t.Errorf("profile changed when parsed again: %+v, want %+v", again, profile)
+			}
+		})
+	}
+}
+
+func TestParseProfileRejectsNewerSchemaVersion(t *testing.T) {
+	data := []byte(fmt.Sprintf("schema_version: %d\nprofile:\n  name: Future\n", CurrentSchemaVersion+1))
+
+	_, _, _, err := parseProfile(data)
+	if !errors.Is(err, ErrUnsupportedSchemaVersion) {
+		t.Fatalf("parseProfile() error = %v, want %v", err, ErrUnsupportedSchemaVersion)
+	}
+}
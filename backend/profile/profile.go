@@ -1,34 +1,180 @@
 package profile
 
+import (
+	"io/fs"
+	"path"
+	"path/filepath"
+)
+
+//go:generate go run ../internal/cmd/profile-schema -src . -o ../../docs/profile.schema.json
+
 type DeviceType string
 
 const (
 	DeviceTypeKeyboard DeviceType = "keyboard"
 	DeviceTypeMouse    DeviceType = "mouse"
+	// DeviceTypeDesk is the device type of a profile for both a keyboard and a mouse, such as a keyboard and
+	// mouse recorded together. Desk profiles have both keys and buttons and can be used for either device.
+	DeviceTypeDesk DeviceType = "desk"
 )
 
+// SupportsKeyboard returns true if the profile can be used for the keyboard.
+func (p *Profile) SupportsKeyboard() bool {
+	return p.Details.DeviceType == DeviceTypeKeyboard || p.Details.DeviceType == DeviceTypeDesk
+}
+
+// SupportsMouse returns true if the profile can be used for the mouse.
+func (p *Profile) SupportsMouse() bool {
+	return p.Details.DeviceType == DeviceTypeMouse || p.Details.DeviceType == DeviceTypeDesk
+}
+
 // ProfileDetails represents the details of a profile.
 type ProfileDetails struct {
-	// The name of the profile.
+	// The name of the profile. This is the canonical name, which profiles are looked up by.
 	Name string `yaml:"name"`
+	// Translations of the name, by locale, such as "de" or "pt-BR". See LocalizedName.
+	NameI18n map[string]string `yaml:"name_i18n,omitempty"`
 	// The author of the profile.
 	Author string `yaml:"author"`
 	// The description of the profile.
 	Description string `yaml:"description"`
+	// Translations of the description, by locale. See LocalizedDescription.
+	DescriptionI18n map[string]string `yaml:"description_i18n,omitempty"`
 	// The type of device for the profile.
 	DeviceType DeviceType `yaml:"device"`
+	// Free-form tags used to group and search for profiles, such as "tactile" or "vintage".
+	Tags []string `yaml:"tags,omitempty"`
+	// The type of switch the profile was recorded from, such as "Cherry MX Brown".
+	SwitchType string `yaml:"switch_type,omitempty"`
+	// The license under which the profile's audio files are distributed.
+	License string `yaml:"license,omitempty"`
+	// The version of the profile, as chosen by its author.
+	Version string `yaml:"version,omitempty"`
+	// A URL with more information about the profile.
+	Homepage string `yaml:"homepage,omitempty"`
+	// The path of an image or audio file previewing the profile, relative to the profile directory.
+	Preview string `yaml:"preview,omitempty"`
+}
+
+// PreviewPath returns the full path of the profile's preview file, or an empty string if the profile does not
+// have one. The path is the preview file joined to Profile.Location, so for profiles that are not stored on disk
+// it is not a path on disk.
+func (p *Profile) PreviewPath() string {
+	if p.Details.Preview == "" {
+		return ""
+	}
+
+	return locationPath(p.Location, p.Details.Preview)
 }
 
 // Profile represents a profile.
 type Profile struct {
+	// The schema version of the profile. Profiles are migrated to CurrentSchemaVersion when loaded.
+	SchemaVersion int `yaml:"schema_version,omitempty"`
+	// The stable identity of the profile. Profiles that do not set an ID explicitly are identified by the
+	// name of the directory they are stored in. Rules and preferences refer to profiles by ID so that
+	// renaming a profile does not break them.
+	ID string `yaml:"id,omitempty"`
 	// The details of the profile.
 	Details ProfileDetails `yaml:"profile"`
+	// The ID or name of the profile that this profile extends, if any. Sources and key
+	// or button mappings that are not defined by this profile are inherited from
+	// the parent profile.
+	Extends string `yaml:"extends,omitempty"`
 	// The sources of the profile.
 	Sources []Source `yaml:"sources"`
 	// The keys of the profile.
 	Keys Keys `yaml:"keys"`
 	// The buttons of the profile.
 	Buttons Buttons `yaml:"buttons"`
-	// The location of the profile.
+	// The location of the profile: its directory on disk for profiles stored on disk, or its slash-separated path
+	// in the file system of its layer for profiles stored in an embedded file system or a zip archive.
 	Location string `yaml:"-"`
+	// The file system rooted at the profile directory that the files of the profile are read from. For profiles
+	// stored on disk, this is os.DirFS of Location.
+	FS fs.FS `yaml:"-"`
+	// The name of the store layer the profile was loaded from.
+	Layer string `yaml:"-"`
+	// Whether the profile was loaded from a read-only layer and cannot be deleted or modified.
+	ReadOnly bool `yaml:"-"`
+	// The locations of the profiles that this profile extends, directly or through its parent, nearest first.
+	ParentLocations []string `yaml:"-"`
+	// The result of validating the structure of the profile when it was loaded. The audio files of the profile
+	// are only decoded by FullValidation.
+	Validation ValidationResult `yaml:"-"`
+
+	// The device type of the profile that this profile extends, once the extends field has been resolved.
+	parentDeviceType DeviceType
+	// The result of fully validating the profile, computed the first time FullValidation is called.
+	fullValidation *lazyValidation
+}
+
+// SourceFilePath returns the path of an audio file referenced by the given source of the profile. The path is
+// the file joined to the location of the source, or to the shared sample library for audio files prefixed with
+// SampleLibraryPrefix, so for sources that are not stored on disk it is not a path on disk. The path is empty if
+// the sample library path is invalid.
+func (p *Profile) SourceFilePath(source Source, fileName string) string {
+	if sample, ok := cutSampleRef(fileName); ok {
+		if _, err := sampleLibraryFS(sample); err != nil {
+			return ""
+		}
+		return locationPath(GetSampleLibraryDir(), sample)
+	}
+
+	_, location := p.sourceLocation(source)
+	return locationPath(location, fileName)
+}
+
+// OpenSourceFile opens an audio file referenced by the given source of the profile, from the file system the
+// source is stored in or from the shared sample library.
+func (p *Profile) OpenSourceFile(source Source, fileName string) (fs.File, error) {
+	fsys, name, err := p.sourceFile(source, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return fsys.Open(name)
+}
+
+// sourceFile returns the file system and path in it of an audio file referenced by the given source of the
+// profile.
+func (p *Profile) sourceFile(source Source, fileName string) (fs.FS, string, error) {
+	if sample, ok := cutSampleRef(fileName); ok {
+		fsys, err := sampleLibraryFS(sample)
+		return fsys, sample, err
+	}
+
+	fsys, _ := p.sourceLocation(source)
+	return fsys, filePath(fileName), nil
+}
+
+// sourceLocation returns the file system and location that the audio files of a source are relative to.
+// Sources added to a profile after it was loaded are relative to the profile.
+func (p *Profile) sourceLocation(source Source) (fs.FS, string) {
+	if source.FS == nil {
+		return p.FS, p.Location
+	}
+
+	return source.FS, source.Location
+}
+
+// setLocation records the file system and location that a profile and its sources are stored in.
+func (p *Profile) setLocation(fsys fs.FS, location string) {
+	p.FS = fsys
+	p.Location = location
+	for i := range p.Sources {
+		p.Sources[i].FS = fsys
+		p.Sources[i].Location = location
+	}
+}
+
+// filePath converts a file name from profile.yaml, relative to the profile directory, to a path in the file
+// system of the profile.
+func filePath(name string) string {
+	return path.Clean(filepath.ToSlash(name))
+}
+
+// locationPath joins a file name from profile.yaml to the location of a profile.
+func locationPath(location string, name string) string {
+	return filepath.Join(location, filepath.FromSlash(name))
 }
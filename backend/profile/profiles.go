@@ -2,145 +2,155 @@ package profile
 
 import (
 	"archive/zip"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"log/slog"
 	"os"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
-	"sync"
 
 	"github.com/google/uuid"
 	"github.com/samber/lo"
-	"gopkg.in/yaml.v2"
 )
 
-var (
-	profilesDir  *string    = nil
-	profiles     []*Profile = make([]*Profile, 0)
-	profilesLock sync.RWMutex
-)
-
-// SetProfilesDir sets the directory where profiles are stored.
+// SetProfilesDir sets the directory where the user's profiles are stored. It replaces the writable layer of the
+// default store; read-only layers, such as system-wide profiles directories and embedded profiles, are kept
+// after it.
 func SetProfilesDir(dir string) {
-	profilesLock.Lock()
-	defer profilesLock.Unlock()
-
-	if profilesDir != nil {
-		if *profilesDir == dir {
-			return
-		}
-	}
+	layers := lo.Filter(defaultStore.Layers(), func(layer Layer, _ int) bool {
+		return layer.readOnly()
+	})
 
-	profiles = make([]*Profile, 0)
-	profilesDir = &dir
+	defaultStore.SetLayers(append([]Layer{{Name: "user", Dir: dir}}, layers...)...)
 }
 
-// LoadProfiles loads all profiles from the current profiles directory.
+// LoadProfiles loads all profiles from the layers of the default store.
 func LoadProfiles() error {
-	profilesLock.Lock()
-	defer profilesLock.Unlock()
+	return defaultStore.Load()
+}
+
+// LoadProfile loads the profile stored in the given directory. The extends
+// field of the profile is not resolved and the profile is not validated.
+func LoadProfile(dir string) (*Profile, error) {
+	return loadProfile(os.DirFS(dir), dir, true)
+}
 
-	if profilesDir == nil {
-		return fmt.Errorf("profiles directory not set")
+// LoadProfileFS loads the profile stored in the given directory of a file system, such as an embedded file
+// system or an open zip archive. The file system must stay available for as long as the profile is used, since
+// its audio files are read from it. The extends field of the profile is not resolved and the profile is not
+// validated.
+func LoadProfileFS(fsys fs.FS, dir string) (*Profile, error) {
+	root, err := fs.Sub(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open profile directory: %w", err)
 	}
 
-	// Clear the profiles slice
-	profiles = make([]*Profile, 0)
+	return loadProfile(root, dir, false)
+}
+
+// loadProfile loads a profile from a file system rooted at the profile directory. The location is recorded in
+// the profile and identifies it if it does not set an ID. Only profiles whose location is a writable directory
+// on disk may be written to, so a profile that is migrated to a newer schema version is only written back if
+// writable is set and write back is enabled.
+func loadProfile(fsys fs.FS, location string, writable bool) (*Profile, error) {
+	profileMetadata, err := fs.ReadFile(fsys, "profile.yaml")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read profile metadata file: %w", err)
+	}
 
-	entries, err := os.ReadDir(*profilesDir)
+	profile, migratedMetadata, migrated, err := parseProfile(profileMetadata)
 	if err != nil {
-		return fmt.Errorf("failed to read profiles directory: %w", err)
-	}
-
-	for _, entry := range entries {
-		if entry.IsDir() {
-			profilePath := filepath.Join(*profilesDir, entry.Name())
-
-			// Ensure that profile.yaml exists
-			profileMetadataFile := filepath.Join(*profilesDir, entry.Name(), "profile.yaml")
-			if _, err := os.Stat(profileMetadataFile); os.IsNotExist(err) {
-				slog.Error("Profile metadata file not found", "path", profilePath)
-				continue
-			}
-
-			// Load the profile metadata
-			profileMetadata, err := os.ReadFile(profileMetadataFile)
-			if err != nil {
-				slog.Error("Failed to read profile metadata file", "path", profileMetadataFile, "error", err)
-				continue
-			}
-
-			var profile Profile
-			err = yaml.Unmarshal(profileMetadata, &profile)
-			if err != nil {
-				slog.Error("Failed to unmarshal profile metadata", "path", profileMetadataFile, "error", err)
-				continue
-			}
-			profile.Location = profilePath
-
-			// Default to keyboard if device type is not set
-			if profile.Details.DeviceType != DeviceTypeMouse {
-				profile.Details.DeviceType = DeviceTypeKeyboard
-			}
-
-			profiles = append(profiles, &profile)
+		return nil, err
+	}
+
+	if migrated && writable && writeBackMigrations.Load() {
+		profileMetadataFile := filepath.Join(location, "profile.yaml")
+		err = writeMigratedProfile(profileMetadataFile, profileMetadata, migratedMetadata)
+		if err != nil {
+			slog.Error("Failed to write migrated profile metadata", "path", profileMetadataFile, "error", err)
 		}
 	}
 
+	profile.setLocation(fsys, location)
+	if profile.ID == "" {
+		profile.ID = path.Base(filepath.ToSlash(location))
+	}
+
+	return profile, nil
+}
+
+// writeMigratedProfile replaces a profile.yaml file with its migrated contents, keeping the original contents
+// in a backup file next to it.
+func writeMigratedProfile(path string, original []byte, migrated []byte) error {
+	err := os.WriteFile(path+".bak", original, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	tempPath := path + ".tmp"
+	err = os.WriteFile(tempPath, migrated, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write migrated profile: %w", err)
+	}
+
+	err = os.Rename(tempPath, path)
+	if err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to replace profile: %w", err)
+	}
+
 	return nil
 }
 
-// GetKeyboardProfiles returns a list of all keyboard profiles.
+// GetKeyboardProfiles returns a list of all profiles that can be used for the keyboard, including desk profiles.
 func GetKeyboardProfiles() []*Profile {
-	return lo.Filter(profiles, func(profile *Profile, _ int) bool {
-		return profile.Details.DeviceType == DeviceTypeKeyboard
+	return lo.Filter(defaultStore.Profiles(), func(profile *Profile, _ int) bool {
+		return profile.SupportsKeyboard()
 	})
 }
 
-// GetMouseProfiles returns a list of all mouse profiles.
+// GetMouseProfiles returns a list of all profiles that can be used for the mouse, including desk profiles.
 func GetMouseProfiles() []*Profile {
-	return lo.Filter(profiles, func(profile *Profile, _ int) bool {
-		return profile.Details.DeviceType == DeviceTypeMouse
+	return lo.Filter(defaultStore.Profiles(), func(profile *Profile, _ int) bool {
+		return profile.SupportsMouse()
 	})
 }
 
 // FindProfileByName finds a profile by name.
 func FindProfileByName(name string) (*Profile, bool) {
-	profilesLock.RLock()
-	defer profilesLock.RUnlock()
-
-	return lo.Find(profiles, func(profile *Profile) bool {
-		return strings.EqualFold(profile.Details.Name, name)
-	})
+	return defaultStore.FindByName(name)
 }
 
-// DeleteProfile deletes a profile by name.
-func DeleteProfile(name string) error {
-	profile, found := FindProfileByName(name)
-	if !found {
-		return fmt.Errorf("profile not found")
-	}
-
-	profilesLock.Lock()
-	defer profilesLock.Unlock()
-
-	err := os.RemoveAll(profile.Location)
-	if err != nil {
-		return fmt.Errorf("failed to delete profile: %w", err)
-	}
+// DeleteProfile deletes a profile by ID or name. Profiles in read-only layers cannot be deleted.
+func DeleteProfile(ref string) error {
+	return defaultStore.Delete(ref)
+}
 
-	profiles = lo.Filter(profiles, func(p *Profile, _ int) bool {
-		return !strings.EqualFold(p.Details.Name, name)
-	})
+// ExportOptions are the options used when exporting a profile.
+type ExportOptions struct {
+	// The private key used to sign the manifest of the archive. The archive is not signed if this is nil.
+	SigningKey ed25519.PrivateKey
+	// The name of the signer recorded in the manifest.
+	Signer string
+}
 
-	return nil
+// ExportProfile exports a profile, by ID or name, to a zip file. The archive includes an unsigned manifest of
+// its files, and the shared samples that the profile uses from the sample library.
+func ExportProfile(ref string, zipPath string) error {
+	return ExportProfileWithOptions(ref, zipPath, ExportOptions{})
 }
 
-// ExportProfile exports a profile to a zip file.
-func ExportProfile(name string, zipPath string) error {
-	profile, found := FindProfileByName(name)
+// ExportProfileWithOptions exports a profile, by ID or name, to a zip file with the given options. The archive includes a
+// manifest of its files, signed if a signing key is provided.
+func ExportProfileWithOptions(ref string, zipPath string, options ExportOptions) error {
+	profile, found := FindProfile(ref)
 	if !found {
 		return fmt.Errorf("profile not found")
 	}
@@ -166,21 +176,33 @@ func ExportProfile(name string, zipPath string) error {
 	zipWriter := zip.NewWriter(zipFile)
 	defer zipWriter.Close()
 
-	// Walk through the profile directory and add files to zip
-	err = filepath.Walk(profile.Location, func(filePath string, info os.FileInfo, err error) error {
+	manifest := Manifest{
+		Version: manifestVersion,
+		Profile: profile.Details.Name,
+		Files:   make(map[string]string),
+	}
+
+	// Walk through the profile directory and add files to zip. Profiles are read through their file system so
+	// that embedded and archived profiles can be exported too.
+	root := profile.FS
+	err = fs.WalkDir(root, ".", func(relPath string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
 		// Skip the directory itself
-		if info.IsDir() {
+		if d.IsDir() {
 			return nil
 		}
 
-		// Calculate relative path from profile location
-		relPath, err := filepath.Rel(profile.Location, filePath)
+		// The manifest is generated below, never copied from the profile directory
+		if relPath == ManifestFileName {
+			return nil
+		}
+
+		info, err := d.Info()
 		if err != nil {
-			return fmt.Errorf("failed to get relative path: %w", err)
+			return fmt.Errorf("failed to read file info: %w", err)
 		}
 
 		// Create a file header
@@ -198,16 +220,18 @@ func ExportProfile(name string, zipPath string) error {
 		}
 
 		// Open and copy the file content
-		file, err := os.Open(filePath)
+		file, err := root.Open(relPath)
 		if err != nil {
 			return fmt.Errorf("failed to open file: %w", err)
 		}
 		defer file.Close()
 
-		_, err = io.Copy(writer, file)
+		hash := sha256.New()
+		_, err = io.Copy(io.MultiWriter(writer, hash), file)
 		if err != nil {
 			return fmt.Errorf("failed to copy file content: %w", err)
 		}
+		manifest.Files[relPath] = hex.EncodeToString(hash.Sum(nil))
 
 		return nil
 	})
@@ -216,19 +240,49 @@ func ExportProfile(name string, zipPath string) error {
 		return fmt.Errorf("failed to zip profile: %w", err)
 	}
 
+	err = exportSamples(profile, zipWriter, &manifest)
+	if err != nil {
+		return fmt.Errorf("failed to zip profile: %w", err)
+	}
+
+	if options.SigningKey != nil {
+		err = manifest.sign(options.Signer, options.SigningKey)
+		if err != nil {
+			return fmt.Errorf("failed to sign manifest: %w", err)
+		}
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode manifest: %w", err)
+	}
+
+	writer, err := zipWriter.Create(ManifestFileName)
+	if err != nil {
+		return fmt.Errorf("failed to create manifest in zip: %w", err)
+	}
+
+	_, err = writer.Write(manifestData)
+	if err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+
 	return nil
 }
 
-// ImportProfile imports a profile from a zip file.
-func ImportProfile(zipPath string) error {
-	if profilesDir == nil {
-		return fmt.Errorf("profiles directory not set")
+// ImportProfile imports a profile from a zip file. If the archive contains a manifest, its files are verified
+// against it and the import is rejected if they do not match. Shared samples in the archive are added to the
+// sample library, unless the library already has a sample with the same contents.
+func ImportProfile(zipPath string) (*ImportResult, error) {
+	profilesDir, err := defaultStore.WritableDir()
+	if err != nil {
+		return nil, err
 	}
 
 	// Open the zip file
 	zipReader, err := zip.OpenReader(zipPath)
 	if err != nil {
-		return fmt.Errorf("failed to open zip file: %w", err)
+		return nil, fmt.Errorf("failed to open zip file: %w", err)
 	}
 	defer zipReader.Close()
 
@@ -236,11 +290,11 @@ func ImportProfile(zipPath string) error {
 	// system temp directory is not writable; use the profiles directory instead.
 	tempParent := ""
 	if runtime.GOOS == "darwin" {
-		tempParent = *profilesDir
+		tempParent = profilesDir
 	}
 	tempDir, err := os.MkdirTemp(tempParent, "profile-import-*")
 	if err != nil {
-		return fmt.Errorf("failed to create temporary directory: %w", err)
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
 	}
 	shouldCleanup := true
 	defer func() {
@@ -251,88 +305,49 @@ func ImportProfile(zipPath string) error {
 	}()
 
 	// Extract all files from the zip to temporary directory
-	for _, file := range zipReader.File {
-		// Construct the file path
-		filePath := filepath.Join(tempDir, file.Name)
-
-		// Check for ZipSlip vulnerability by ensuring the resolved path is within the target directory
-		absTargetDir, err := filepath.Abs(tempDir)
-		if err != nil {
-			return fmt.Errorf("failed to get absolute path of target directory: %w", err)
-		}
-		absFilePath, err := filepath.Abs(filePath)
-		if err != nil {
-			return fmt.Errorf("failed to get absolute path of file: %w", err)
-		}
-		relPath, err := filepath.Rel(absTargetDir, absFilePath)
-		if err != nil || strings.HasPrefix(relPath, "..") {
-			return fmt.Errorf("invalid file path: %s", file.Name)
-		}
-
-		// Create directory structure if needed
-		if file.FileInfo().IsDir() {
-			err = os.MkdirAll(filePath, file.FileInfo().Mode())
-			if err != nil {
-				return fmt.Errorf("failed to create directory: %w", err)
-			}
-			continue
-		}
-
-		// Ensure parent directory exists
-		err = os.MkdirAll(filepath.Dir(filePath), 0755)
-		if err != nil {
-			return fmt.Errorf("failed to create parent directory: %w", err)
-		}
-
-		// Create the file
-		outFile, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, file.FileInfo().Mode())
-		if err != nil {
-			return fmt.Errorf("failed to create file: %w", err)
-		}
-
-		// Open file from zip
-		fileReader, err := file.Open()
-		if err != nil {
-			outFile.Close()
-			return fmt.Errorf("failed to open file in zip: %w", err)
-		}
+	err = extractArchive(&zipReader.Reader, tempDir, GetImportLimits())
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract profile archive: %w", err)
+	}
 
-		// Copy file content
-		_, err = io.Copy(outFile, fileReader)
-		outFile.Close()
-		fileReader.Close()
-		if err != nil {
-			return fmt.Errorf("failed to extract file: %w", err)
-		}
+	// Verify the extracted files against the manifest, if present
+	result, err := verifyManifest(tempDir)
+	if err != nil {
+		return nil, err
 	}
 
-	// Read profile.yaml to get the profile name
-	profileYamlPath := filepath.Join(tempDir, "profile.yaml")
-	profileMetadata, err := os.ReadFile(profileYamlPath)
+	// Move the shared samples in the archive into the sample library
+	samples, err := importSamples(tempDir)
 	if err != nil {
-		return fmt.Errorf("failed to read profile.yaml: %w", err)
+		return nil, err
 	}
+	defer func() {
+		if shouldCleanup {
+			samples.rollback()
+		}
+	}()
+	result.SharedSamplesAdded = len(samples.added)
+	result.SharedSamplesDeduplicated = samples.deduplicated
 
-	var profile Profile
-	err = yaml.Unmarshal(profileMetadata, &profile)
+	// Read profile.yaml to get the profile name
+	profile, err := LoadProfile(tempDir)
 	if err != nil {
-		return fmt.Errorf("failed to unmarshal profile metadata: %w", err)
+		return nil, err
 	}
 
-	// Check if a profile with the same name already exists
-	_, exists := FindProfileByName(profile.Details.Name)
-	if exists {
-		return fmt.Errorf("profile with name '%s' already exists", profile.Details.Name)
+	err = checkImportable(profile, profile.ID != filepath.Base(tempDir))
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate UUID for the new profile directory
 	profileUUID := uuid.New().String()
-	newProfileDir := filepath.Join(*profilesDir, profileUUID)
+	newProfileDir := filepath.Join(profilesDir, profileUUID)
 
 	// Move the temporary directory to the final location
 	err = os.Rename(tempDir, newProfileDir)
 	if err != nil {
-		return fmt.Errorf("failed to move profile to final location: %w", err)
+		return nil, fmt.Errorf("failed to move profile to final location: %w", err)
 	}
 
 	// Mark that we don't need to clean up since we successfully moved the directory
@@ -341,7 +356,94 @@ func ImportProfile(zipPath string) error {
 	// Reload profiles to include the newly imported one
 	err = LoadProfiles()
 	if err != nil {
-		return fmt.Errorf("failed to reload profiles after import: %w", err)
+		return nil, fmt.Errorf("failed to reload profiles after import: %w", err)
+	}
+
+	result.Profile = profile.Details.Name
+	return &result, nil
+}
+
+// ImportProfileFS imports the profile stored in the given directory of a file system, such as a profile
+// embedded in a binary, by copying it to the first writable layer of the default store. The file system is
+// trusted, so the import limits that apply to archives are not enforced; use ImportProfile for archives from
+// untrusted sources.
+func ImportProfileFS(fsys fs.FS, dir string) (*Profile, error) {
+	profilesDir, err := defaultStore.WritableDir()
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := LoadProfileFS(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	explicitID := profile.ID != path.Base(dir)
+	err = checkImportable(profile, explicitID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The profile is copied next to its final location and moved into place, so that a partial copy is never
+	// loaded as a profile.
+	tempDir, err := os.MkdirTemp(profilesDir, ".profile-import-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	err = copyProfileFS(profile.FS, tempDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy profile: %w", err)
+	}
+
+	id := uuid.New().String()
+	err = os.Rename(tempDir, filepath.Join(profilesDir, id))
+	if err != nil {
+		return nil, fmt.Errorf("failed to move profile to final location: %w", err)
+	}
+
+	err = LoadProfiles()
+	if err != nil {
+		return nil, fmt.Errorf("failed to reload profiles after import: %w", err)
+	}
+
+	if explicitID {
+		id = profile.ID
+	}
+
+	imported, ok := FindProfileByID(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrProfileNotFound, id)
+	}
+
+	return imported, nil
+}
+
+// checkImportable returns an error if a profile cannot be imported, because it would fail to play once imported
+// or its name, or its ID if it sets one explicitly, is already used by a loaded profile.
+func checkImportable(profile *Profile, explicitID bool) error {
+	// Reject profiles that would fail to play once imported
+	resolved, err := resolveAgainstLoaded(profile)
+	if err != nil {
+		return err
+	}
+	err = Validate(resolved).Err()
+	if err != nil {
+		return err
+	}
+
+	// Check if a profile with the same name already exists
+	_, exists := FindProfileByName(profile.Details.Name)
+	if exists {
+		return fmt.Errorf("profile with name '%s' already exists", profile.Details.Name)
+	}
+
+	// Profiles without an explicit ID are identified by their new directory, but an explicit ID must be unique
+	if explicitID {
+		if _, exists := FindProfileByID(profile.ID); exists {
+			return fmt.Errorf("profile with id '%s' already exists", profile.ID)
+		}
 	}
 
 	return nil
@@ -0,0 +1,339 @@
+package profile
+
+import (
+	"archive/zip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v2"
+)
+
+// SampleLibraryPrefix is the prefix of the audio files of a source that refer to a sample in the shared sample
+// library rather than to a file in the profile directory, such as "lib:thock/press1.wav". Profiles that share
+// samples refer to them in the library instead of each storing a copy.
+const SampleLibraryPrefix = "lib:"
+
+// archiveSampleLibraryDir is the directory of profile archives that the shared samples used by the profile are
+// stored in, by their path in the sample library.
+const archiveSampleLibraryDir = "sample-library"
+
+// ErrInvalidSamplePath is returned when a reference to the shared sample library is not a valid relative path.
+var ErrInvalidSamplePath = errors.New("invalid sample library path")
+
+var (
+	sampleLibraryDir  string
+	sampleLibraryLock sync.RWMutex
+)
+
+// SetSampleLibraryDir sets the directory of the shared sample library, which audio files prefixed with
+// SampleLibraryPrefix are relative to.
+func SetSampleLibraryDir(dir string) {
+	sampleLibraryLock.Lock()
+	defer sampleLibraryLock.Unlock()
+	sampleLibraryDir = dir
+}
+
+// GetSampleLibraryDir returns the directory of the shared sample library, or an empty string if it is not set.
+func GetSampleLibraryDir() string {
+	sampleLibraryLock.RLock()
+	defer sampleLibraryLock.RUnlock()
+	return sampleLibraryDir
+}
+
+// cutSampleRef returns the path in the shared sample library of an audio file that refers to a shared sample,
+// and whether it does.
+func cutSampleRef(fileName string) (string, bool) {
+	return strings.CutPrefix(fileName, SampleLibraryPrefix)
+}
+
+// sampleLibraryFS returns the file system of the shared sample library, given the slash-separated path of a
+// sample in it. The path must be a valid path in the library.
+func sampleLibraryFS(sample string) (fs.FS, error) {
+	if !fs.ValidPath(sample) || sample == "." {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSamplePath, sample)
+	}
+
+	dir := GetSampleLibraryDir()
+	if dir == "" {
+		return nil, fmt.Errorf("sample library directory not set")
+	}
+
+	return os.DirFS(dir), nil
+}
+
+// referencedSamples returns the paths in the shared sample library of the samples used by the sources of a
+// profile, sorted and without duplicates.
+func referencedSamples(p *Profile) []string {
+	seen := make(map[string]bool)
+	for _, source := range p.Sources {
+		config, err := source.GetSourceConfig()
+		if err != nil {
+			continue
+		}
+
+		for _, file := range sourceFiles(config) {
+			if sample, ok := cutSampleRef(file); ok {
+				seen[sample] = true
+			}
+		}
+	}
+
+	samples := make([]string, 0, len(seen))
+	for sample := range seen {
+		samples = append(samples, sample)
+	}
+	sort.Strings(samples)
+
+	return samples
+}
+
+// exportSamples adds the shared samples used by a profile to a profile archive, so that the archive can be
+// imported on a system whose sample library does not have them, and records their hashes in the manifest.
+func exportSamples(p *Profile, zipWriter *zip.Writer, manifest *Manifest) error {
+	for _, sample := range referencedSamples(p) {
+		library, err := sampleLibraryFS(sample)
+		if err != nil {
+			return err
+		}
+
+		file, err := library.Open(sample)
+		if err != nil {
+			return fmt.Errorf("failed to open shared sample %s: %w", sample, err)
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to stat shared sample %s: %w", sample, err)
+		}
+
+		name := path.Join(archiveSampleLibraryDir, sample)
+		hash := sha256.New()
+		writer, err := zipWriter.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: info.ModTime()})
+		if err == nil {
+			_, err = io.Copy(io.MultiWriter(writer, hash), file)
+		}
+		file.Close()
+		if err != nil {
+			return fmt.Errorf("failed to add shared sample %s: %w", sample, err)
+		}
+
+		manifest.Files[name] = hex.EncodeToString(hash.Sum(nil))
+	}
+
+	return nil
+}
+
+// importedSamples records the shared samples installed into the sample library while importing a profile.
+type importedSamples struct {
+	// The paths on disk of the samples that were added to the library.
+	added []string
+	// The number of samples that were already in the library.
+	deduplicated int
+}
+
+// rollback removes the samples that were added to the library, for when the import fails.
+func (s importedSamples) rollback() {
+	for _, file := range s.added {
+		os.Remove(file)
+	}
+}
+
+// importSamples moves the shared samples in the sample-library directory of a profile extracted into dir into
+// the shared sample library. Samples are deduplicated by content: a sample that the library already has, at any
+// path, is not added again, and a sample whose path is already used by a different sample is added under a name
+// that includes its hash. The references in profile.yaml are rewritten to match.
+func importSamples(dir string) (importedSamples, error) {
+	imported := importedSamples{added: make([]string, 0)}
+
+	archived := filepath.Join(dir, archiveSampleLibraryDir)
+	if _, err := os.Stat(archived); errors.Is(err, fs.ErrNotExist) {
+		return imported, nil
+	}
+
+	libraryDir := GetSampleLibraryDir()
+	if libraryDir == "" {
+		return imported, fmt.Errorf("sample library directory not set")
+	}
+
+	library, err := hashSampleLibrary(libraryDir)
+	if err != nil {
+		return imported, err
+	}
+
+	renames := make(map[string]string)
+	err = filepath.WalkDir(archived, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(archived, file)
+		if err != nil {
+			return err
+		}
+		sample := filepath.ToSlash(rel)
+
+		hash, err := hashFile(file)
+		if err != nil {
+			return err
+		}
+
+		if existing, ok := library[hash]; ok {
+			renames[sample] = existing
+			imported.deduplicated++
+			return nil
+		}
+
+		target := sample
+		if _, err := os.Stat(filepath.Join(libraryDir, rel)); err == nil {
+			ext := path.Ext(sample)
+			target = strings.TrimSuffix(sample, ext) + "-" + hash[:12] + ext
+		}
+
+		dst := filepath.Join(libraryDir, filepath.FromSlash(target))
+		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+			return err
+		}
+		if err := copyNewFile(file, dst); err != nil {
+			return err
+		}
+
+		imported.added = append(imported.added, dst)
+		library[hash] = target
+		renames[sample] = target
+		return nil
+	})
+	if err != nil {
+		imported.rollback()
+		return importedSamples{}, fmt.Errorf("failed to add shared samples to the sample library: %w", err)
+	}
+
+	err = updateProfileDocument(dir, func(doc yaml.MapSlice) yaml.MapSlice {
+		return rewriteSampleRefs(doc, renames)
+	})
+	if err != nil {
+		imported.rollback()
+		return importedSamples{}, err
+	}
+
+	if err := os.RemoveAll(archived); err != nil {
+		imported.rollback()
+		return importedSamples{}, fmt.Errorf("failed to remove shared samples from profile: %w", err)
+	}
+
+	return imported, nil
+}
+
+// hashSampleLibrary returns the path in the library of every sample in the shared sample library, by content
+// hash.
+func hashSampleLibrary(libraryDir string) (map[string]string, error) {
+	library := make(map[string]string)
+
+	err := filepath.WalkDir(libraryDir, func(file string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && file == libraryDir {
+			return filepath.SkipDir
+		}
+		if err != nil || !d.Type().IsRegular() {
+			return err
+		}
+
+		rel, err := filepath.Rel(libraryDir, file)
+		if err != nil {
+			return err
+		}
+
+		hash, err := hashFile(file)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := library[hash]; !ok {
+			library[hash] = filepath.ToSlash(rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read sample library: %w", err)
+	}
+
+	return library, nil
+}
+
+// sampleFileFields are the fields of the audio files of a source or layer in a raw profile.yaml document.
+var sampleFileFields = []string{"press", "release", "repeat", "hold", "release_long"}
+
+// rewriteSampleRefs replaces the references to shared samples in the audio files of the sources and their layers
+// in a raw profile.yaml document, according to the given map of old to new paths in the library. Other values,
+// such as the description, are left as they are even if they look like references.
+func rewriteSampleRefs(doc yaml.MapSlice, renames map[string]string) yaml.MapSlice {
+	for i := range doc {
+		if doc[i].Key != "sources" {
+			continue
+		}
+
+		sources, _ := doc[i].Value.([]any)
+		for _, source := range sources {
+			source, _ := source.(yaml.MapSlice)
+			for j := range source {
+				switch source[j].Key {
+				case "source":
+					source[j].Value = rewriteSourceFiles(source[j].Value, renames)
+				case "layers":
+					layers, _ := source[j].Value.([]any)
+					for _, layer := range layers {
+						layer, _ := layer.(yaml.MapSlice)
+						for k := range layer {
+							if layer[k].Key == "source" {
+								layer[k].Value = rewriteSourceFiles(layer[k].Value, renames)
+							}
+						}
+					}
+				}
+			}
+		}
+	}
+
+	return doc
+}
+
+// rewriteSourceFiles replaces the references to shared samples in the raw audio files of a source or layer,
+// which are either the name of a single press audio file or a map of audio files.
+func rewriteSourceFiles(value any, renames map[string]string) any {
+	switch v := value.(type) {
+	case string:
+		return rewriteSampleRef(v, renames)
+	case yaml.MapSlice:
+		for i := range v {
+			file, ok := v[i].Value.(string)
+			if ok && lo.Contains(sampleFileFields, fmt.Sprint(v[i].Key)) {
+				v[i].Value = rewriteSampleRef(file, renames)
+			}
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+// rewriteSampleRef replaces a reference to a shared sample according to the given map of old to new paths in
+// the library. Audio files that are not references to renamed samples are returned as they are.
+func rewriteSampleRef(file string, renames map[string]string) string {
+	if sample, ok := cutSampleRef(file); ok {
+		if renamed, ok := renames[sample]; ok {
+			return SampleLibraryPrefix + renamed
+		}
+	}
+
+	return file
+}
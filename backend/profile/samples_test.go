@@ -0,0 +1,65 @@
+package profile
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestRewriteSampleRefs(t *testing.T) {
+	doc := `profile:
+  name: Shared
+  description: lib:thock/press.wav
+  tags:
+  - lib:thock/press.wav
+sources:
+- id: press
+  source: lib:thock/press.wav
+- id: enter
+  source:
+    press: lib:thock/press.wav
+    release: lib:thock/release.wav
+    hold: local.wav
+  layers:
+  - max_interval_ms: 80
+    source: lib:thock/press.wav
+- id: other
+  source: lib:other/press.wav
+`
+	want := `profile:
+  name: Shared
+  description: lib:thock/press.wav
+  tags:
+  - lib:thock/press.wav
+sources:
+- id: press
+  source: lib:thock/press-2.wav
+- id: enter
+  source:
+    press: lib:thock/press-2.wav
+    release: lib:thock/release-2.wav
+    hold: local.wav
+  layers:
+  - max_interval_ms: 80
+    source: lib:thock/press-2.wav
+- id: other
+  source: lib:other/press.wav
+`
+
+	var parsed yaml.MapSlice
+	if err := yaml.Unmarshal([]byte(doc), &parsed); err != nil {
+		t.Fatal(err)
+	}
+
+	renames := map[string]string{
+		"thock/press.wav":   "thock/press-2.wav",
+		"thock/release.wav": "thock/release-2.wav",
+	}
+	got, err := yaml.Marshal(rewriteSampleRefs(parsed, renames))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Errorf("rewriteSampleRefs() =\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,190 @@
+package profile
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// SortOrder is the order in which search results are returned.
+type SortOrder string
+
+const (
+	// SortByRelevance orders results by how well they match the query, then by name. It is the default when a
+	// query is given.
+	SortByRelevance SortOrder = "relevance"
+	// SortByName orders results by name. It is the default when no query is given.
+	SortByName SortOrder = "name"
+	// SortByAuthor orders results by author, then by name.
+	SortByAuthor SortOrder = "author"
+	// SortByDevice orders results by device type, then by name.
+	SortByDevice SortOrder = "device"
+)
+
+// SearchFilters narrows down the profiles returned by Search. Empty fields do not filter.
+type SearchFilters struct {
+	// Only return profiles that have all of these tags.
+	Tags []string
+	// Only return profiles by this author.
+	Author string
+	// Only return profiles for this type of device.
+	DeviceType DeviceType
+	// Only return profiles recorded from this type of switch.
+	SwitchType string
+	// The order of the results.
+	SortBy SortOrder
+	// The locale whose translated names results are ordered by. Empty orders by the canonical names.
+	Locale string
+}
+
+// Weights of the fields a query term can match, used to order results by relevance.
+const (
+	nameMatchWeight        = 8
+	tagMatchWeight         = 4
+	switchTypeMatchWeight  = 3
+	authorMatchWeight      = 2
+	descriptionMatchWeight = 1
+)
+
+// Search returns the loaded profiles that match the query and filters. The query is split into terms, and a
+// profile matches if every term is found in its name, tags, switch type, author or description. Names and
+// descriptions match in any of their translations. Matching is case-insensitive.
+func Search(query string, filters SearchFilters) []*Profile {
+	candidates := defaultStore.Profiles()
+
+	terms := strings.Fields(strings.ToLower(query))
+
+	scores := make(map[*Profile]int, len(candidates))
+	results := make([]*Profile, 0, len(candidates))
+	for _, p := range candidates {
+		if !matchesFilters(p, filters) {
+			continue
+		}
+
+		score, ok := relevance(p, terms)
+		if !ok {
+			continue
+		}
+
+		scores[p] = score
+		results = append(results, p)
+	}
+
+	sortBy := filters.SortBy
+	if sortBy == "" {
+		sortBy = lo.Ternary(len(terms) > 0, SortByRelevance, SortByName)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		switch sortBy {
+		case SortByRelevance:
+			if scores[a] != scores[b] {
+				return scores[a] > scores[b]
+			}
+		case SortByAuthor:
+			if c := compareFold(a.Details.Author, b.Details.Author); c != 0 {
+				return c < 0
+			}
+		case SortByDevice:
+			if a.Details.DeviceType != b.Details.DeviceType {
+				return a.Details.DeviceType < b.Details.DeviceType
+			}
+		}
+		return compareFold(a.LocalizedName(filters.Locale), b.LocalizedName(filters.Locale)) < 0
+	})
+
+	return results
+}
+
+// matchesFilters returns true if the profile passes all of the filters.
+func matchesFilters(p *Profile, filters SearchFilters) bool {
+	if filters.DeviceType != "" && p.Details.DeviceType != filters.DeviceType {
+		return false
+	}
+
+	if filters.Author != "" && !strings.EqualFold(p.Details.Author, filters.Author) {
+		return false
+	}
+
+	if filters.SwitchType != "" && !strings.EqualFold(p.Details.SwitchType, filters.SwitchType) {
+		return false
+	}
+
+	return lo.EveryBy(filters.Tags, func(tag string) bool {
+		return p.HasTag(tag)
+	})
+}
+
+// relevance returns how well the profile matches the query terms, and false if any term does not match.
+func relevance(p *Profile, terms []string) (int, bool) {
+	name := strings.ToLower(withTranslations(p.Details.Name, p.Details.NameI18n))
+	author := strings.ToLower(p.Details.Author)
+	description := strings.ToLower(withTranslations(p.Details.Description, p.Details.DescriptionI18n))
+	switchType := strings.ToLower(p.Details.SwitchType)
+
+	score := 0
+	for _, term := range terms {
+		termScore := 0
+		if strings.Contains(name, term) {
+			termScore += nameMatchWeight
+		}
+		if lo.ContainsBy(p.Details.Tags, func(tag string) bool {
+			return strings.Contains(strings.ToLower(tag), term)
+		}) {
+			termScore += tagMatchWeight
+		}
+		if strings.Contains(switchType, term) {
+			termScore += switchTypeMatchWeight
+		}
+		if strings.Contains(author, term) {
+			termScore += authorMatchWeight
+		}
+		if strings.Contains(description, term) {
+			termScore += descriptionMatchWeight
+		}
+
+		if termScore == 0 {
+			return 0, false
+		}
+		score += termScore
+	}
+
+	return score, true
+}
+
+// HasTag returns true if the profile has the given tag. Tags are compared case-insensitively.
+func (p *Profile) HasTag(tag string) bool {
+	return lo.ContainsBy(p.Details.Tags, func(t string) bool {
+		return strings.EqualFold(strings.TrimSpace(t), strings.TrimSpace(tag))
+	})
+}
+
+// ListTags returns every tag used by the loaded profiles, sorted and without duplicates.
+func ListTags() []string {
+	seen := make(map[string]string)
+	for _, p := range defaultStore.Profiles() {
+		for _, tag := range p.Details.Tags {
+			tag = strings.TrimSpace(tag)
+			if tag == "" {
+				continue
+			}
+			if _, ok := seen[strings.ToLower(tag)]; !ok {
+				seen[strings.ToLower(tag)] = tag
+			}
+		}
+	}
+
+	tags := lo.Values(seen)
+	sort.Slice(tags, func(i, j int) bool {
+		return compareFold(tags[i], tags[j]) < 0
+	})
+
+	return tags
+}
+
+// compareFold compares two strings case-insensitively.
+func compareFold(a string, b string) int {
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
@@ -0,0 +1,49 @@
+package profile
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// SoundRef refers to the sources played for a key or button. In profile.yaml it is either a single source ID
+// or a list of source IDs, in which case one of the sources is picked at random each time a sound is played.
+type SoundRef []string
+
+// UnmarshalYAML parses a SoundRef from either a single source ID or a list of source IDs.
+func (r *SoundRef) UnmarshalYAML(unmarshal func(any) error) error {
+	var id string
+	if err := unmarshal(&id); err == nil {
+		if id == "" {
+			*r = SoundRef{}
+		} else {
+			*r = SoundRef{id}
+		}
+		return nil
+	}
+
+	var ids []string
+	if err := unmarshal(&ids); err != nil {
+		return fmt.Errorf("invalid sound source: must be a source ID or a list of source IDs")
+	}
+
+	*r = ids
+	return nil
+}
+
+// MarshalYAML writes a SoundRef referring to a single source as its source ID, and any other SoundRef as a list.
+func (r SoundRef) MarshalYAML() (any, error) {
+	if len(r) == 1 {
+		return r[0], nil
+	}
+
+	return []string(r), nil
+}
+
+// Pick returns one of the referenced source IDs at random, or an empty string if no source is referenced.
+func (r SoundRef) Pick() string {
+	if len(r) == 0 {
+		return ""
+	}
+
+	return r[rand.Intn(len(r))]
+}
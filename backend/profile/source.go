@@ -1,6 +1,16 @@
 package profile
 
-import "fmt"
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"sort"
+	"time"
+)
+
+// DefaultHoldThreshold is how long a key must be held down before the hold audio file of a source is played,
+// when the source does not set a threshold itself.
+const DefaultHoldThreshold = 500 * time.Millisecond
 
 // SourceConfig represents the configuration of a source.
 type SourceConfig struct {
@@ -8,50 +18,272 @@ type SourceConfig struct {
 	Press *string `yaml:"press"`
 	// The audio file to play when the key is released.
 	Release *string `yaml:"release"`
+	// The audio file to play for key repeat events while the key is held down. When not set, the press audio
+	// file is played for key repeat events.
+	Repeat *string `yaml:"repeat"`
+	// The audio file to play once the key has been held down for the hold threshold.
+	Hold *string `yaml:"hold"`
+	// The audio file to play instead of the release audio file when the key is released after being held down
+	// for at least the hold threshold.
+	ReleaseLong *string `yaml:"release_long"`
+	// How long the key must be held down, in milliseconds, before the hold and release_long audio files apply.
+	// When zero, DefaultHoldThreshold is used.
+	HoldThresholdMS int `yaml:"hold_threshold_ms"`
+	// The gain, pitch and volume adjustments of the source. These are set from the fields of the Source.
+	Adjustments SourceAdjustments `yaml:"-"`
+	// The velocity layers of the source, ordered from the shortest maximum interval to the longest.
+	Layers []LayerConfig `yaml:"-"`
+}
+
+// LayerConfig represents the configuration of a velocity layer of a source.
+type LayerConfig struct {
+	// The longest time since the previous key press for which the layer is used.
+	MaxInterval time.Duration
+	// The audio files of the layer.
+	Files SourceConfig
+}
+
+// ForInterval returns the source configuration to use for a key pressed the given time after the previous key
+// press. This is the layer with the shortest maximum interval that the interval does not exceed, or the source
+// itself if there is no such layer or the interval is zero. The adjustments of the source apply to every layer.
+func (c SourceConfig) ForInterval(interval time.Duration) SourceConfig {
+	if interval <= 0 {
+		return c
+	}
+
+	for _, layer := range c.Layers {
+		if interval <= layer.MaxInterval {
+			files := layer.Files
+			files.Adjustments = c.Adjustments
+			return files
+		}
+	}
+
+	return c
+}
+
+// SourceLayer represents a velocity layer of a source.
+type SourceLayer struct {
+	// The longest time since the previous key press, in milliseconds, for which the layer is used.
+	MaxIntervalMS int `yaml:"max_interval_ms"`
+	// The audio files of the layer. Audio files are relative to the profile directory, or to the shared sample
+	// library when prefixed with "lib:", such as "lib:thock/press1.wav".
+	Source SourceFiles `yaml:"source"`
+}
+
+// SourceAdjustments are the playback adjustments applied to a source on top of the global audio effects.
+type SourceAdjustments struct {
+	// The gain of the source in decibels.
+	GainDB float64
+	// The range of the pitch shift of the source in semitones. A value is picked from the range at random each
+	// time the source plays.
+	PitchSemitones PitchRange
+	// The maximum random variation of the volume of the source, as a fraction of its volume between 0 and 1.
+	VolumeJitter float64
+}
+
+// Gain returns the gain of the source as a linear volume multiplier.
+func (a SourceAdjustments) Gain() float64 {
+	return math.Pow(10, a.GainDB/20)
+}
+
+// HoldThreshold returns how long a key must be held down before the hold and release_long audio files apply.
+func (c SourceConfig) HoldThreshold() time.Duration {
+	if c.HoldThresholdMS <= 0 {
+		return DefaultHoldThreshold
+	}
+
+	return time.Duration(c.HoldThresholdMS) * time.Millisecond
+}
+
+// PitchRange is a range of pitch shifts in semitones. In profile.yaml it is either a single value or a
+// [min, max] range.
+type PitchRange [2]float64
+
+// UnmarshalYAML parses a PitchRange from either a single value or a [min, max] range.
+func (r *PitchRange) UnmarshalYAML(unmarshal func(any) error) error {
+	var value float64
+	if err := unmarshal(&value); err == nil {
+		*r = PitchRange{value, value}
+		return nil
+	}
+
+	var values []float64
+	if err := unmarshal(&values); err != nil {
+		return fmt.Errorf("invalid pitch_semitones: must be a number or a [min, max] range")
+	}
+	if len(values) != 2 {
+		return fmt.Errorf("invalid pitch_semitones: a range must have exactly two values")
+	}
+
+	*r = PitchRange{values[0], values[1]}
+	return nil
+}
+
+// MarshalYAML writes a PitchRange with equal bounds as a single value, and any other PitchRange as a range.
+func (r PitchRange) MarshalYAML() (any, error) {
+	if r[0] == r[1] {
+		return r[0], nil
+	}
+
+	return []float64{r[0], r[1]}, nil
+}
+
+// IsZero returns true if the range does not shift the pitch, so that it is omitted from profile.yaml.
+func (r PitchRange) IsZero() bool {
+	return r == PitchRange{}
 }
 
 // Source represents a source in a profile.
 type Source struct {
 	// The ID of the source.
 	ID string `yaml:"id"`
-	// The source configuration.
-	Source any `yaml:"source"`
+	// The audio files of the source. Audio files are relative to the profile directory, or to the shared sample
+	// library when prefixed with "lib:", such as "lib:thock/press1.wav".
+	Source SourceFiles `yaml:"source"`
+	// The gain of the source in decibels, used to balance sources that are louder or quieter than the others.
+	GainDB float64 `yaml:"gain_db,omitempty"`
+	// The pitch shift of the source in semitones, either a single value or a [min, max] range from which a
+	// value is picked at random each time the source plays.
+	PitchSemitones PitchRange `yaml:"pitch_semitones,omitempty"`
+	// The maximum random variation of the volume of the source, as a fraction of its volume between 0 and 1.
+	VolumeJitter float64 `yaml:"volume_jitter,omitempty"`
+	// The velocity layers of the source. Real switches sound different when typed on quickly and lightly than
+	// when pressed slowly and deliberately, so a layer replaces the audio files of the source when a key is
+	// pressed soon after the previous key press. The source itself is used for slower presses.
+	Layers []SourceLayer `yaml:"layers,omitempty"`
+	// The directory that the audio files of the source are relative to. This is
+	// the location of the profile that defined the source, which for sources
+	// inherited through Profile.Extends is the location of the parent profile.
+	Location string `yaml:"-"`
+	// The file system rooted at Location that the audio files of the source are read from, or nil for sources
+	// added to a profile after it was loaded, whose audio files are relative to the profile.
+	FS fs.FS `yaml:"-"`
 }
 
-// GetSourceConfig gets the source configuration for a source.
+// GetAdjustments gets the gain, pitch and volume adjustments of a source.
+func (s *Source) GetAdjustments() (SourceAdjustments, error) {
+	if s.PitchSemitones[0] > s.PitchSemitones[1] {
+		return SourceAdjustments{}, fmt.Errorf("invalid pitch_semitones: %v is greater than %v", s.PitchSemitones[0], s.PitchSemitones[1])
+	}
+
+	if s.VolumeJitter < 0 || s.VolumeJitter > 1 {
+		return SourceAdjustments{}, fmt.Errorf("invalid volume_jitter %v: must be between 0 and 1", s.VolumeJitter)
+	}
+
+	return SourceAdjustments{
+		GainDB:         s.GainDB,
+		PitchSemitones: s.PitchSemitones,
+		VolumeJitter:   s.VolumeJitter,
+	}, nil
+}
+
+// GetSourceConfig gets the source configuration for a source, including its adjustments.
 func (s *Source) GetSourceConfig() (SourceConfig, error) {
-	switch s.Source.(type) {
-	case string:
-		srcString := s.Source.(string)
-		return SourceConfig{
-			Press:   &srcString,
-			Release: nil,
-		}, nil
-	case map[any]any:
-		sourceConfig := s.Source.(map[any]any)
-
-		var (
-			press   *string
-			release *string
-		)
-
-		if pressAny, ok := sourceConfig["press"]; ok {
-			if pressStr, ok := pressAny.(string); ok {
-				press = &pressStr
-			}
+	adjustments, err := s.GetAdjustments()
+	if err != nil {
+		return SourceConfig{}, err
+	}
+
+	sourceConfig, err := s.getFiles()
+	if err != nil {
+		return SourceConfig{}, err
+	}
+
+	sourceConfig.Adjustments = adjustments
+	return sourceConfig, nil
+}
+
+// getFiles gets the audio files, hold threshold and layers of a source.
+func (s *Source) getFiles() (SourceConfig, error) {
+	sourceConfig, err := parseSourceFiles(s.Source)
+	if err != nil {
+		return SourceConfig{}, err
+	}
+
+	for i, layer := range s.Layers {
+		if layer.MaxIntervalMS <= 0 {
+			return SourceConfig{}, fmt.Errorf("invalid max_interval_ms for layer %d: %v", i, layer.MaxIntervalMS)
 		}
 
-		if releaseAny, ok := sourceConfig["release"]; ok {
-			if releaseStr, ok := releaseAny.(string); ok {
-				release = &releaseStr
-			}
+		files, err := parseSourceFiles(layer.Source)
+		if err != nil {
+			return SourceConfig{}, fmt.Errorf("invalid source for layer %d: %w", i, err)
 		}
 
-		return SourceConfig{
-			Press:   press,
-			Release: release,
-		}, nil
-	default:
-		return SourceConfig{}, fmt.Errorf("invalid source type: %T", s.Source)
+		sourceConfig.Layers = append(sourceConfig.Layers, LayerConfig{
+			MaxInterval: time.Duration(layer.MaxIntervalMS) * time.Millisecond,
+			Files:       files,
+		})
+	}
+
+	// Layers are matched from the shortest interval to the longest.
+	sort.SliceStable(sourceConfig.Layers, func(i, j int) bool {
+		return sourceConfig.Layers[i].MaxInterval < sourceConfig.Layers[j].MaxInterval
+	})
+
+	return sourceConfig, nil
+}
+
+// SourceFiles represents the audio files and hold threshold of a source or layer. In profile.yaml it is either
+// the name of a single press audio file or a map of audio files.
+type SourceFiles struct {
+	// The audio file to play when the key is pressed.
+	Press *string `yaml:"press,omitempty"`
+	// The audio file to play when the key is released.
+	Release *string `yaml:"release,omitempty"`
+	// The audio file to play for key repeat events while the key is held down.
+	Repeat *string `yaml:"repeat,omitempty"`
+	// The audio file to play once the key has been held down for the hold threshold.
+	Hold *string `yaml:"hold,omitempty"`
+	// The audio file to play instead of the release audio file when the key is released after being held down.
+	ReleaseLong *string `yaml:"release_long,omitempty"`
+	// How long the key must be held down, in milliseconds, before the hold and release_long audio files apply.
+	HoldThresholdMS int `yaml:"hold_threshold_ms,omitempty"`
+}
+
+// UnmarshalYAML parses SourceFiles from either the name of a single press audio file or a map of audio files.
+func (f *SourceFiles) UnmarshalYAML(unmarshal func(any) error) error {
+	var press string
+	if err := unmarshal(&press); err == nil {
+		*f = SourceFiles{Press: &press}
+		return nil
+	}
+
+	// The plain type does not implement yaml.Unmarshaler, so the map is decoded field by field.
+	type plain SourceFiles
+	var files plain
+	if err := unmarshal(&files); err != nil {
+		return fmt.Errorf("invalid source: must be an audio file name or a map of audio files: %w", err)
 	}
+
+	*f = SourceFiles(files)
+	return nil
+}
+
+// MarshalYAML writes SourceFiles with only a press audio file as the name of the file, and any other
+// SourceFiles as a map.
+func (f SourceFiles) MarshalYAML() (any, error) {
+	if f.Press != nil && f.Release == nil && f.Repeat == nil && f.Hold == nil && f.ReleaseLong == nil && f.HoldThresholdMS == 0 {
+		return *f.Press, nil
+	}
+
+	type plain SourceFiles
+	return plain(f), nil
+}
+
+// parseSourceFiles converts the audio files and hold threshold of a source or layer to a source configuration.
+func parseSourceFiles(files SourceFiles) (SourceConfig, error) {
+	if files.HoldThresholdMS < 0 {
+		return SourceConfig{}, fmt.Errorf("invalid hold_threshold_ms: %v", files.HoldThresholdMS)
+	}
+
+	return SourceConfig{
+		Press:           files.Press,
+		Release:         files.Release,
+		Repeat:          files.Repeat,
+		Hold:            files.Hold,
+		ReleaseLong:     files.ReleaseLong,
+		HoldThresholdMS: files.HoldThresholdMS,
+	}, nil
 }
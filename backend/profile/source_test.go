@@ -0,0 +1,72 @@
+package profile
+
+import (
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestPitchRangeYAML(t *testing.T) {
+	tests := []struct {
+		name     string
+		yaml     string
+		want     PitchRange
+		wantErr  bool
+		wantYAML string
+	}{
+		{name: "omitted", yaml: "id: press", want: PitchRange{}, wantYAML: "id: press\n"},
+		{name: "integer", yaml: "id: press\npitch_semitones: -2", want: PitchRange{-2, -2}, wantYAML: "id: press\npitch_semitones: -2\n"},
+		{name: "float", yaml: "id: press\npitch_semitones: 1.5", want: PitchRange{1.5, 1.5}, wantYAML: "id: press\npitch_semitones: 1.5\n"},
+		{name: "range", yaml: "id: press\npitch_semitones: [-1, 0.5]", want: PitchRange{-1, 0.5}, wantYAML: "id: press\npitch_semitones:\n- -1\n- 0.5\n"},
+		{name: "one value", yaml: "id: press\npitch_semitones: [1]", wantErr: true},
+		{name: "three values", yaml: "id: press\npitch_semitones: [1, 2, 3]", wantErr: true},
+		{name: "not a number", yaml: "id: press\npitch_semitones: high", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var source struct {
+				ID             string     `yaml:"id"`
+				PitchSemitones PitchRange `yaml:"pitch_semitones,omitempty"`
+			}
+			err := yaml.Unmarshal([]byte(tt.yaml), &source)
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "pitch_semitones") {
+					t.Fatalf("Unmarshal() error = %v, want an invalid pitch_semitones error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if source.PitchSemitones != tt.want {
+				t.Errorf("pitch_semitones = %v, want %v", source.PitchSemitones, tt.want)
+			}
+
+			data, err := yaml.Marshal(source)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+			if string(data) != tt.wantYAML {
+				t.Errorf("Marshal() = %q, want %q", data, tt.wantYAML)
+			}
+		})
+	}
+}
+
+func TestSourceGetAdjustmentsPitch(t *testing.T) {
+	source := Source{ID: "press", PitchSemitones: PitchRange{2, -2}}
+	if _, err := source.GetAdjustments(); err == nil {
+		t.Errorf("GetAdjustments() error = nil, want an error for a range with min greater than max")
+	}
+
+	source.PitchSemitones = PitchRange{-2, 2}
+	adjustments, err := source.GetAdjustments()
+	if err != nil {
+		t.Fatalf("GetAdjustments() error = %v", err)
+	}
+	if adjustments.PitchSemitones != source.PitchSemitones {
+		t.Errorf("pitch = %v, want %v", adjustments.PitchSemitones, source.PitchSemitones)
+	}
+}
@@ -0,0 +1,304 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+)
+
+var (
+	// ErrReadOnlyProfile is returned when a profile stored in a read-only layer is deleted or modified.
+	ErrReadOnlyProfile = errors.New("profile is read-only")
+	// ErrNoWritableLayer is returned when a profile is added to a store that has no writable layer.
+	ErrNoWritableLayer = errors.New("no writable profiles directory")
+)
+
+// Layer is a directory of profiles in a ProfileStore.
+type Layer struct {
+	// The name of the layer, such as "user" or "system", recorded in the profiles loaded from it.
+	Name string
+	// The directory the profiles of the layer are stored in. Each profile is a subdirectory. For layers with
+	// an FS, this is a slash-separated path in FS, such as "." for the root.
+	Dir string
+	// Whether the profiles of the layer can be deleted or modified. Profiles in read-only layers can still be
+	// duplicated into a writable layer.
+	ReadOnly bool
+	// The file system the layer is stored in, such as an embedded file system or an open zip archive, or nil
+	// if the layer is a directory on disk. Layers with a file system are always read-only.
+	FS fs.FS
+}
+
+// readOnly returns true if the profiles of the layer cannot be deleted or modified.
+func (l Layer) readOnly() bool {
+	return l.ReadOnly || l.FS != nil
+}
+
+// dirFS returns a file system rooted at the directory of the layer. Layers on disk use os.DirFS of Dir.
+func (l Layer) dirFS() (fs.FS, error) {
+	if l.FS == nil {
+		return os.DirFS(l.Dir), nil
+	}
+
+	return fs.Sub(l.FS, l.Dir)
+}
+
+// location returns the location of a profile of the layer, given the name of its directory.
+func (l Layer) location(name string) string {
+	if l.FS == nil {
+		return filepath.Join(l.Dir, name)
+	}
+
+	return path.Join(l.Dir, name)
+}
+
+// sameLayer returns true if two layers are known to be the same. Layers with a file system are never known to
+// be the same, since file systems cannot always be compared.
+func sameLayer(a Layer, b Layer) bool {
+	return a.Name == b.Name && a.Dir == b.Dir && a.ReadOnly == b.ReadOnly && a.FS == nil && b.FS == nil
+}
+
+// ProfileStore loads profiles from an ordered list of layers, such as a per-user directory followed by a
+// read-only system-wide directory. When profiles in more than one layer have the same ID, the profile in the
+// earliest layer is used and the others are shadowed, so that a user can override a system profile by storing
+// a profile with the same ID in their own directory. Profiles may extend profiles from any layer.
+type ProfileStore struct {
+	lock     sync.RWMutex
+	layers   []Layer
+	profiles []*Profile
+}
+
+// defaultStore is the store used by the package level functions, such as LoadProfiles and FindProfile.
+var defaultStore = NewProfileStore()
+
+// DefaultStore returns the store used by the package level functions, such as LoadProfiles and FindProfile.
+func DefaultStore() *ProfileStore {
+	return defaultStore
+}
+
+// NewProfileStore creates a store that loads profiles from the given layers, in order of precedence. The
+// profiles are not loaded until Load is called.
+func NewProfileStore(layers ...Layer) *ProfileStore {
+	return &ProfileStore{
+		layers:   append([]Layer{}, layers...),
+		profiles: make([]*Profile, 0),
+	}
+}
+
+// Layers returns the layers of the store, in order of precedence.
+func (s *ProfileStore) Layers() []Layer {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return append([]Layer{}, s.layers...)
+}
+
+// SetLayers replaces the layers of the store. The loaded profiles are cleared if the layers change and must be
+// loaded again with Load.
+func (s *ProfileStore) SetLayers(layers ...Layer) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if slices.EqualFunc(s.layers, layers, sameLayer) {
+		return
+	}
+
+	s.layers = append([]Layer{}, layers...)
+	s.profiles = make([]*Profile, 0)
+}
+
+// WritableDir returns the directory of the first writable layer, which new profiles are added to.
+func (s *ProfileStore) WritableDir() (string, error) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	layer, ok := lo.Find(s.layers, func(l Layer) bool { return !l.readOnly() })
+	if !ok {
+		return "", ErrNoWritableLayer
+	}
+
+	return layer.Dir, nil
+}
+
+// Load loads the profiles of every layer, replacing the profiles loaded before. Read-only layers whose directory
+// does not exist are skipped, so that optional system-wide directories do not need to be installed. Profiles in
+// read-only layers are never rewritten, even if they are migrated to a newer schema version.
+func (s *ProfileStore) Load() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if len(s.layers) == 0 {
+		return fmt.Errorf("profiles directory not set")
+	}
+
+	// Clear the profiles slice
+	s.profiles = make([]*Profile, 0)
+
+	loaded := make([]*Profile, 0)
+	for _, layer := range s.layers {
+		layerFS, err := layer.dirFS()
+		if err != nil {
+			return fmt.Errorf("failed to open profiles directory %s: %w", layer.Dir, err)
+		}
+
+		entries, err := fs.ReadDir(layerFS, ".")
+		if err != nil {
+			if layer.readOnly() && errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("failed to read profiles directory %s: %w", layer.Dir, err)
+		}
+
+		// Profiles shadowed by an earlier layer are only skipped once the whole layer has been read, so that
+		// duplicate IDs within a layer are still reported.
+		layerProfiles := make([]*Profile, 0, len(entries))
+		for _, entry := range entries {
+			// Hidden directories hold profiles that are still being copied into the layer.
+			if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				continue
+			}
+
+			profilePath := layer.location(entry.Name())
+			profileFS, err := fs.Sub(layerFS, entry.Name())
+			if err != nil {
+				slog.Error("Failed to open profile directory", "path", profilePath, "error", err)
+				continue
+			}
+
+			// Ensure that profile.yaml exists
+			if _, err := fs.Stat(profileFS, "profile.yaml"); errors.Is(err, fs.ErrNotExist) {
+				slog.Error("Profile metadata file not found", "path", profilePath)
+				continue
+			}
+
+			// Load the profile metadata
+			profile, err := loadProfile(profileFS, profilePath, !layer.readOnly())
+			if err != nil {
+				slog.Error("Failed to load profile", "path", profilePath, "error", err)
+				continue
+			}
+			profile.Layer = layer.Name
+			profile.ReadOnly = layer.readOnly()
+
+			if duplicate, ok := lo.Find(layerProfiles, func(p *Profile) bool { return p.ID == profile.ID }); ok {
+				slog.Error("Duplicate profile ID", "id", profile.ID, "path", profilePath, "existing", duplicate.Location)
+				continue
+			}
+
+			layerProfiles = append(layerProfiles, profile)
+		}
+
+		for _, profile := range layerProfiles {
+			if shadowing, ok := lo.Find(loaded, func(p *Profile) bool { return p.ID == profile.ID }); ok {
+				slog.Info("Profile shadowed by a profile in an earlier layer", "id", profile.ID, "path", profile.Location, "shadowedBy", shadowing.Location)
+				continue
+			}
+
+			loaded = append(loaded, profile)
+		}
+	}
+
+	// Resolve inheritance before applying defaults so that a profile which does
+	// not set a device type inherits the device type of its parent.
+	for _, profile := range resolveExtends(loaded) {
+		// Default to keyboard if device type is not set
+		if profile.Details.DeviceType != DeviceTypeMouse && profile.Details.DeviceType != DeviceTypeDesk {
+			profile.Details.DeviceType = DeviceTypeKeyboard
+		}
+
+		// Decoding every audio file is too slow to do on every load, so only the structure of the profile is
+		// checked here and the audio files are checked on demand by FullValidation.
+		profile.Validation = ValidateStructure(profile)
+		profile.fullValidation = &lazyValidation{}
+		if !profile.Validation.Valid() {
+			slog.Warn("Profile failed validation", "path", profile.Location, "error", profile.Validation.Err())
+		}
+
+		s.profiles = append(s.profiles, profile)
+	}
+
+	return nil
+}
+
+// Profiles returns a copy of the list of loaded profiles.
+func (s *ProfileStore) Profiles() []*Profile {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	loaded := make([]*Profile, len(s.profiles))
+	copy(loaded, s.profiles)
+	return loaded
+}
+
+// FindByID finds a loaded profile by its ID.
+func (s *ProfileStore) FindByID(id string) (*Profile, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return lo.Find(s.profiles, func(profile *Profile) bool {
+		return profile.ID == id
+	})
+}
+
+// FindByName finds a loaded profile by name.
+func (s *ProfileStore) FindByName(name string) (*Profile, bool) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return lo.Find(s.profiles, func(profile *Profile) bool {
+		return strings.EqualFold(profile.Details.Name, name)
+	})
+}
+
+// Find finds a loaded profile by reference. The reference is matched against profile IDs first and profile
+// names second.
+func (s *ProfileStore) Find(ref string) (*Profile, bool) {
+	if profile, ok := s.FindByID(ref); ok {
+		return profile, true
+	}
+
+	return s.FindByName(ref)
+}
+
+// Delete deletes a profile by ID or name. Profiles in read-only layers cannot be deleted.
+func (s *ProfileStore) Delete(ref string) error {
+	profile, found := s.Find(ref)
+	if !found {
+		return fmt.Errorf("%w: %s", ErrProfileNotFound, ref)
+	}
+
+	if err := checkWritable(profile); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	err := os.RemoveAll(profile.Location)
+	if err != nil {
+		return fmt.Errorf("failed to delete profile: %w", err)
+	}
+
+	s.profiles = lo.Filter(s.profiles, func(p *Profile, _ int) bool {
+		return p != profile
+	})
+
+	return nil
+}
+
+// checkWritable returns ErrReadOnlyProfile if a profile is stored in a read-only layer.
+func checkWritable(profile *Profile) error {
+	if profile.ReadOnly {
+		return fmt.Errorf("%w: %s is stored in the %s profiles directory", ErrReadOnlyProfile, profile.Details.Name, profile.Layer)
+	}
+
+	return nil
+}
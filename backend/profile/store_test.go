@@ -0,0 +1,109 @@
+package profile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestProfileStoreLoadsDiskAndFSLayers(t *testing.T) {
+	userDir := t.TempDir()
+	childDir := filepath.Join(userDir, "child")
+	if err := os.MkdirAll(filepath.Join(childDir, "audio"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	err := os.WriteFile(filepath.Join(childDir, "profile.yaml"), []byte(`
+profile:
+  name: Child
+extends: parent
+sources:
+  - id: enter
+    source: audio/enter.wav
+keys:
+  default: [press]
+  other:
+    - sound: enter
+      keys: [enter]
+`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(childDir, "audio", "enter.wav"), []byte("enter"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	bundled := fstest.MapFS{
+		"profiles/parent/profile.yaml": {Data: []byte(`
+profile:
+  name: Parent
+sources:
+  - id: press
+    source: press.wav
+keys:
+  default: [press]
+`)},
+		"profiles/parent/press.wav": {Data: []byte("press")},
+	}
+
+	store := NewProfileStore(
+		Layer{Name: "user", Dir: userDir},
+		Layer{Name: "bundled", Dir: "profiles", FS: bundled},
+	)
+	if err := store.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	parent, ok := store.FindByID("parent")
+	if !ok {
+		t.Fatalf("profile from the file system layer was not loaded")
+	}
+	if parent.Location != "profiles/parent" || !parent.ReadOnly || parent.Layer != "bundled" {
+		t.Errorf("parent location = %q, read-only = %v, layer = %q", parent.Location, parent.ReadOnly, parent.Layer)
+	}
+
+	child, ok := store.FindByID("child")
+	if !ok {
+		t.Fatalf("profile from the disk layer was not loaded")
+	}
+	if child.Location != childDir || child.ReadOnly || child.Layer != "user" {
+		t.Errorf("child location = %q, read-only = %v, layer = %q", child.Location, child.ReadOnly, child.Layer)
+	}
+	if !child.Validation.Valid() {
+		t.Errorf("child validation = %v", child.Validation.Err())
+	}
+
+	// Both the child's own source and the source inherited from the file system layer are read through the
+	// file system of the profile that defined them.
+	want := map[string]struct {
+		file     string
+		path     string
+		contents string
+	}{
+		"enter": {"audio/enter.wav", filepath.Join(childDir, "audio", "enter.wav"), "enter"},
+		"press": {"press.wav", filepath.Join("profiles", "parent", "press.wav"), "press"},
+	}
+	for _, source := range child.Sources {
+		w, ok := want[source.ID]
+		if !ok {
+			t.Errorf("unexpected source %q", source.ID)
+			continue
+		}
+
+		if got := child.SourceFilePath(source, w.file); got != w.path {
+			t.Errorf("SourceFilePath(%s) = %q, want %q", source.ID, got, w.path)
+		}
+
+		file, err := child.OpenSourceFile(source, w.file)
+		if err != nil {
+			t.Errorf("OpenSourceFile(%s) error = %v", source.ID, err)
+			continue
+		}
+		data, err := io.ReadAll(file)
+		file.Close()
+		if err != nil || string(data) != w.contents {
+			t.Errorf("OpenSourceFile(%s) read %q, %v, want %q", source.ID, data, err, w.contents)
+		}
+	}
+}
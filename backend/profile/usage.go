@@ -0,0 +1,249 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// audioFileExtensions are the extensions of the files that are considered audio files when looking for
+// orphaned files.
+var audioFileExtensions = []string{".wav", ".mp3"}
+
+// DiskUsage reports the disk usage of a profile and the audio files that are out of sync with its sources.
+type DiskUsage struct {
+	// The ID of the profile.
+	ProfileID string
+	// The location of the profile.
+	Location string
+	// The total size of the files in the profile directory, in bytes.
+	TotalBytes int64
+	// The number of files in the profile directory.
+	FileCount int
+	// The audio files in the profile directory that no source or preview of any loaded profile references.
+	OrphanedFiles []FileUsage
+	// The total size of the orphaned files, in bytes.
+	OrphanedBytes int64
+	// The audio files referenced by the sources of the profile that do not exist.
+	MissingFiles []MissingFile
+}
+
+// FileUsage represents a file in a profile directory.
+type FileUsage struct {
+	// The path of the file, relative to the profile directory.
+	Path string
+	// The size of the file in bytes.
+	Size int64
+}
+
+// MissingFile represents an audio file that is referenced by a source but does not exist.
+type MissingFile struct {
+	// The path of the file, relative to the directory of the source.
+	Path string
+	// The ID of the source that references the file.
+	SourceID string
+}
+
+// CleanupResult reports the files removed from a profile directory.
+type CleanupResult struct {
+	// The paths of the removed files, relative to the profile directory.
+	Removed []string
+	// The total size of the removed files, in bytes.
+	RemovedBytes int64
+}
+
+// GetDiskUsage returns the disk usage of every loaded profile, sorted by size from largest to smallest.
+func GetDiskUsage() ([]DiskUsage, error) {
+	loaded := loadedProfiles()
+	referenced, unresolved := referencedAudioFiles(loaded)
+
+	usages := make([]DiskUsage, 0, len(loaded))
+	for _, p := range loaded {
+		usage, err := diskUsage(p, referenced, unresolved)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get disk usage of profile %s: %w", p.Details.Name, err)
+		}
+		usages = append(usages, usage)
+	}
+
+	sort.SliceStable(usages, func(i, j int) bool {
+		return usages[i].TotalBytes > usages[j].TotalBytes
+	})
+
+	return usages, nil
+}
+
+// GetProfileDiskUsage returns the disk usage of a profile, by ID or name.
+func GetProfileDiskUsage(ref string) (DiskUsage, error) {
+	p, ok := FindProfile(ref)
+	if !ok {
+		return DiskUsage{}, fmt.Errorf("%w: %s", ErrProfileNotFound, ref)
+	}
+
+	referenced, unresolved := referencedAudioFiles(loadedProfiles())
+	return diskUsage(p, referenced, unresolved)
+}
+
+// RemoveOrphanedFiles removes the audio files in the directory of a profile, by ID or name, that no source of
+// any loaded profile references. Files that are still referenced by a profile extending the profile are kept.
+// Missing files are reported by GetProfileDiskUsage but are not changed. Profiles in read-only layers cannot
+// be cleaned up.
+func RemoveOrphanedFiles(ref string) (CleanupResult, error) {
+	if p, ok := FindProfile(ref); ok {
+		if err := checkWritable(p); err != nil {
+			return CleanupResult{}, err
+		}
+	}
+
+	usage, err := GetProfileDiskUsage(ref)
+	if err != nil {
+		return CleanupResult{}, err
+	}
+
+	result := CleanupResult{Removed: make([]string, 0, len(usage.OrphanedFiles))}
+	for _, file := range usage.OrphanedFiles {
+		err := os.Remove(filepath.Join(usage.Location, file.Path))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return result, fmt.Errorf("failed to remove orphaned file %s: %w", file.Path, err)
+		}
+
+		result.Removed = append(result.Removed, file.Path)
+		result.RemovedBytes += file.Size
+	}
+
+	return result, nil
+}
+
+// diskUsage returns the disk usage of a profile, given the full paths of the audio files referenced by the
+// loaded profiles and the locations whose references could not be resolved.
+func diskUsage(p *Profile, referenced map[string]bool, unresolved map[string]bool) (DiskUsage, error) {
+	usage := DiskUsage{
+		ProfileID:     p.ID,
+		Location:      p.Location,
+		OrphanedFiles: make([]FileUsage, 0),
+		MissingFiles:  make([]MissingFile, 0),
+	}
+
+	err := fs.WalkDir(p.FS, ".", func(rel string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		usage.TotalBytes += info.Size()
+		usage.FileCount++
+
+		// Files cannot be reported as orphaned when the sources in the directory could not all be read.
+		path := locationPath(p.Location, rel)
+		if unresolved[fileKey(p.Location)] || !isAudioFile(path) || referenced[fileKey(path)] {
+			return nil
+		}
+
+		usage.OrphanedFiles = append(usage.OrphanedFiles, FileUsage{Path: rel, Size: info.Size()})
+		usage.OrphanedBytes += info.Size()
+		return nil
+	})
+	if err != nil {
+		return DiskUsage{}, fmt.Errorf("failed to read profile directory: %w", err)
+	}
+
+	for _, source := range p.Sources {
+		config, err := source.GetSourceConfig()
+		if err != nil {
+			continue
+		}
+
+		for _, file := range sourceFiles(config) {
+			fsys, name, err := p.sourceFile(source, file)
+			if err != nil {
+				continue
+			}
+			if _, err := fs.Stat(fsys, name); errors.Is(err, fs.ErrNotExist) {
+				usage.MissingFiles = append(usage.MissingFiles, MissingFile{Path: file, SourceID: source.ID})
+			}
+		}
+	}
+
+	return usage, nil
+}
+
+// referencedAudioFiles returns the full paths of the audio files referenced by the sources and previews of the
+// given profiles, and the locations of sources whose audio files could not be read.
+func referencedAudioFiles(loaded []*Profile) (map[string]bool, map[string]bool) {
+	referenced := make(map[string]bool)
+	unresolved := make(map[string]bool)
+
+	for _, p := range loaded {
+		if preview := p.PreviewPath(); preview != "" {
+			referenced[fileKey(preview)] = true
+		}
+
+		for _, source := range p.Sources {
+			config, err := source.GetSourceConfig()
+			if err != nil {
+				unresolved[fileKey(lo.Ternary(source.Location != "", source.Location, p.Location))] = true
+				continue
+			}
+
+			for _, file := range sourceFiles(config) {
+				referenced[fileKey(p.SourceFilePath(source, file))] = true
+			}
+		}
+	}
+
+	return referenced, unresolved
+}
+
+// loadedProfiles returns a copy of the list of loaded profiles.
+func loadedProfiles() []*Profile {
+	return defaultStore.Profiles()
+}
+
+// fileKey returns the key that a path is compared by. Paths are compared case-insensitively on Windows and
+// macOS, whose file systems are case-insensitive by default, so that a file is not reported as orphaned when a
+// source refers to it with a different case.
+func fileKey(path string) string {
+	path = filepath.Clean(path)
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		return strings.ToLower(path)
+	}
+
+	return path
+}
+
+// sourceFiles returns the names of the audio files of a source configuration, including those of its layers.
+func sourceFiles(config SourceConfig) []string {
+	files := make([]string, 0)
+	for _, file := range []*string{config.Press, config.Release, config.Repeat, config.Hold, config.ReleaseLong} {
+		if file != nil {
+			files = append(files, *file)
+		}
+	}
+
+	for _, layer := range config.Layers {
+		files = append(files, sourceFiles(layer.Files)...)
+	}
+
+	return lo.Uniq(files)
+}
+
+// isAudioFile returns true if the file has the extension of an audio file.
+func isAudioFile(path string) bool {
+	return lo.ContainsBy(audioFileExtensions, func(ext string) bool {
+		return strings.EqualFold(filepath.Ext(path), ext)
+	})
+}
@@ -0,0 +1,459 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gopxl/beep/v2/mp3"
+	"github.com/gopxl/beep/v2/wav"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/key"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/listener/listenertypes"
+	"github.com/samber/lo"
+)
+
+// Severity represents the severity of a validation diagnostic.
+type Severity string
+
+const (
+	// SeverityError marks a problem that prevents the profile from working.
+	SeverityError Severity = "error"
+	// SeverityWarning marks a problem that does not prevent the profile from working,
+	// but that is most likely a mistake.
+	SeverityWarning Severity = "warning"
+)
+
+// maxRecommendedGainDB is the largest source gain, in either direction, that is not reported as a likely
+// mistake.
+const maxRecommendedGainDB = 24
+
+// Diagnostic represents a single problem found while validating a profile.
+type Diagnostic struct {
+	// The severity of the problem.
+	Severity Severity `json:"severity"`
+	// The field of the profile that the problem was found in, for example "sources[2].source.press".
+	Field string `json:"field"`
+	// A human readable description of the problem.
+	Message string `json:"message"`
+}
+
+// String returns the diagnostic formatted as "field: message".
+func (d Diagnostic) String() string {
+	if d.Field == "" {
+		return d.Message
+	}
+
+	return fmt.Sprintf("%s: %s", d.Field, d.Message)
+}
+
+// ValidationResult represents the result of validating a profile.
+type ValidationResult struct {
+	// Problems that prevent the profile from working.
+	Errors []Diagnostic `json:"errors"`
+	// Problems that do not prevent the profile from working.
+	Warnings []Diagnostic `json:"warnings"`
+}
+
+// Valid returns true if no errors were found.
+func (r ValidationResult) Valid() bool {
+	return len(r.Errors) == 0
+}
+
+// Err returns a *ValidationError describing the errors that were found, or nil if the profile is valid.
+func (r ValidationResult) Err() error {
+	if r.Valid() {
+		return nil
+	}
+
+	return &ValidationError{Errors: r.Errors}
+}
+
+func (r *ValidationResult) errorf(field string, format string, args ...any) {
+	r.Errors = append(r.Errors, Diagnostic{
+		Severity: SeverityError,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (r *ValidationResult) warnf(field string, format string, args ...any) {
+	r.Warnings = append(r.Warnings, Diagnostic{
+		Severity: SeverityWarning,
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// ValidationError is returned when a profile fails validation.
+type ValidationError struct {
+	// The errors that were found.
+	Errors []Diagnostic
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return "invalid profile: " + strings.Join(lo.Map(e.Errors, func(d Diagnostic, _ int) string {
+		return d.String()
+	}), "; ")
+}
+
+// ErrUnsupportedAudioFormat is returned when an audio file does not have a supported extension.
+var ErrUnsupportedAudioFormat = errors.New("unsupported audio format")
+
+// Validate validates a profile and returns any errors and warnings that were found.
+//
+// Besides checking the structure of the profile, Validate checks that every source ID referenced by the
+// key and button mappings is defined, that every audio file exists and can be decoded, and that key and
+// button names are known.
+func Validate(p *Profile) ValidationResult {
+	return validate(p, true)
+}
+
+// ValidateStructure validates a profile like Validate, but without opening or decoding its audio files. It is
+// cheap enough to run whenever profiles are loaded.
+func ValidateStructure(p *Profile) ValidationResult {
+	return validate(p, false)
+}
+
+// lazyValidation holds the result of fully validating a loaded profile, so that its audio files are decoded at
+// most once per load.
+type lazyValidation struct {
+	once   sync.Once
+	result ValidationResult
+}
+
+// FullValidation returns the result of fully validating the profile with Validate, including decoding its audio
+// files. For loaded profiles the result is computed the first time it is needed and reused until the profiles are
+// loaded again.
+func (p *Profile) FullValidation() ValidationResult {
+	if p.fullValidation == nil {
+		return Validate(p)
+	}
+
+	p.fullValidation.once.Do(func() {
+		p.fullValidation.result = Validate(p)
+	})
+	return p.fullValidation.result
+}
+
+// validate validates a profile, decoding its audio files if checkAudio is set.
+func validate(p *Profile, checkAudio bool) ValidationResult {
+	var result ValidationResult
+
+	if strings.TrimSpace(p.Details.Name) == "" {
+		result.errorf("profile.name", "profile name is required")
+	}
+
+	switch p.Details.DeviceType {
+	case "", DeviceTypeKeyboard, DeviceTypeMouse, DeviceTypeDesk:
+	default:
+		result.errorf("profile.device", "unknown device type %q", p.Details.DeviceType)
+	}
+
+	if p.parentDeviceType != "" && effectiveDeviceType(p.Details.DeviceType) != p.parentDeviceType {
+		result.errorf("profile.device", "device type %q does not match the device type %q of the extended profile %s",
+			effectiveDeviceType(p.Details.DeviceType), p.parentDeviceType, p.Extends)
+	}
+
+	validateDetails(p, &result)
+
+	sourceIDs := validateSources(p, checkAudio, &result)
+
+	switch p.Details.DeviceType {
+	case DeviceTypeDesk:
+		validateKeys(p, sourceIDs, &result)
+		validateButtons(p, sourceIDs, &result)
+	case DeviceTypeMouse:
+		validateButtons(p, sourceIDs, &result)
+
+		if len(p.Keys.Default) > 0 || len(p.Keys.Other) > 0 {
+			result.warnf("keys", "keys section is ignored for mouse profiles")
+		}
+	default:
+		validateKeys(p, sourceIDs, &result)
+
+		if len(p.Buttons.Default) > 0 || len(p.Buttons.Other) > 0 {
+			result.warnf("buttons", "buttons section is ignored for keyboard profiles")
+		}
+	}
+
+	return result
+}
+
+// validateDetails validates the optional metadata of a profile.
+func validateDetails(p *Profile, result *ValidationResult) {
+	if p.Details.Homepage != "" {
+		u, err := url.Parse(p.Details.Homepage)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			result.warnf("profile.homepage", "homepage %q is not an http or https URL", p.Details.Homepage)
+		}
+	}
+
+	if p.Details.Preview != "" {
+		if _, err := fs.Stat(p.FS, filePath(p.Details.Preview)); err != nil {
+			result.warnf("profile.preview", "preview file %q not found", p.Details.Preview)
+		}
+	}
+
+	for i, tag := range p.Details.Tags {
+		if strings.TrimSpace(tag) == "" {
+			result.warnf(fmt.Sprintf("profile.tags[%d]", i), "tag is empty")
+		}
+	}
+
+	validateTranslations(p.Details.NameI18n, "profile.name_i18n", result)
+	validateTranslations(p.Details.DescriptionI18n, "profile.description_i18n", result)
+}
+
+// validateTranslations validates the locales and values of a set of translations.
+func validateTranslations(translations map[string]string, field string, result *ValidationResult) {
+	for locale, value := range translations {
+		if NormalizeLocale(locale) == "" {
+			result.warnf(field, "invalid locale %q", locale)
+		}
+		if strings.TrimSpace(value) == "" {
+			result.warnf(fmt.Sprintf("%s.%s", field, locale), "translation is empty")
+		}
+	}
+}
+
+// validateSources validates the sources of a profile and returns the set of defined source IDs. The audio files
+// of the sources are only opened and decoded if checkAudio is set.
+func validateSources(p *Profile, checkAudio bool, result *ValidationResult) map[string]bool {
+	sourceIDs := make(map[string]bool, len(p.Sources))
+
+	if len(p.Sources) == 0 {
+		result.errorf("sources", "profile has no sources")
+	}
+
+	for i, source := range p.Sources {
+		field := fmt.Sprintf("sources[%d]", i)
+
+		if source.ID == "" {
+			result.errorf(field+".id", "source ID is required")
+		} else if sourceIDs[source.ID] {
+			result.errorf(field+".id", "duplicate source ID %q", source.ID)
+		}
+		sourceIDs[source.ID] = true
+
+		adjustments, err := source.GetAdjustments()
+		if err != nil {
+			result.errorf(field, "%v", err)
+			continue
+		}
+
+		if math.Abs(adjustments.GainDB) > maxRecommendedGainDB {
+			result.warnf(field+".gain_db", "gain of %vdB is outside the recommended range of ±%vdB", adjustments.GainDB, maxRecommendedGainDB)
+		}
+
+		sourceConfig, err := source.GetSourceConfig()
+		if err != nil {
+			result.errorf(field+".source", "%v", err)
+			continue
+		}
+
+		hasFiles := validateSourceFiles(p, source, sourceConfig, checkAudio, field+".source", result)
+		if !hasFiles {
+			result.warnf(field+".source", "source %q has no audio files", source.ID)
+		}
+
+		intervals := make(map[int]bool, len(source.Layers))
+		for j, layer := range source.Layers {
+			layerField := fmt.Sprintf("%s.layers[%d]", field, j)
+			if intervals[layer.MaxIntervalMS] {
+				result.warnf(layerField+".max_interval_ms", "duplicate layer interval %dms", layer.MaxIntervalMS)
+			}
+			intervals[layer.MaxIntervalMS] = true
+
+			// Layers are sorted by interval in the source configuration, so they are parsed again here to
+			// report problems against the layer as written.
+			layerConfig, err := parseSourceFiles(layer.Source)
+			if err != nil {
+				continue
+			}
+
+			if !validateSourceFiles(p, source, layerConfig, checkAudio, layerField+".source", result) {
+				result.warnf(layerField+".source", "layer has no audio files")
+			}
+		}
+	}
+
+	return sourceIDs
+}
+
+// validateSourceFiles validates the audio files of a source configuration and returns whether it has any.
+func validateSourceFiles(p *Profile, source Source, sourceConfig SourceConfig, checkAudio bool, field string, result *ValidationResult) bool {
+	files := []struct {
+		field string
+		file  *string
+	}{
+		{"press", sourceConfig.Press},
+		{"release", sourceConfig.Release},
+		{"repeat", sourceConfig.Repeat},
+		{"hold", sourceConfig.Hold},
+		{"release_long", sourceConfig.ReleaseLong},
+	}
+
+	hasFiles := false
+	for _, f := range files {
+		if f.file == nil {
+			continue
+		}
+
+		hasFiles = true
+		if checkAudio {
+			validateAudioFile(p, source, *f.file, field+"."+f.field, result)
+		}
+	}
+
+	return hasFiles
+}
+
+// validateAudioFile checks that an audio file referenced by a source exists and can be decoded.
+func validateAudioFile(p *Profile, source Source, fileName string, field string, result *ValidationResult) {
+	file, err := p.OpenSourceFile(source, fileName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			result.errorf(field, "audio file %s not found", fileName)
+		} else if errors.Is(err, ErrInvalidSamplePath) {
+			result.errorf(field, "%v", err)
+		} else {
+			result.errorf(field, "failed to read audio file %s: %v", fileName, err)
+		}
+		return
+	}
+	defer file.Close()
+
+	if err := decodeAudioFile(file, fileName); err != nil {
+		result.errorf(field, "failed to decode audio file %s: %v", fileName, err)
+	}
+}
+
+// decodeAudioFile checks that an audio file can be decoded. The format is determined by the extension of name.
+func decodeAudioFile(file io.ReadCloser, name string) error {
+	var (
+		streamer io.Closer
+		err      error
+	)
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".wav":
+		streamer, _, err = wav.Decode(file)
+	case ".mp3":
+		streamer, _, err = mp3.Decode(file)
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedAudioFormat, filepath.Ext(name))
+	}
+	if err != nil {
+		return err
+	}
+
+	return streamer.Close()
+}
+
+// validateKeys validates the keys section of a keyboard profile.
+func validateKeys(p *Profile, sourceIDs map[string]bool, result *ValidationResult) {
+	for i, id := range p.Keys.Default {
+		if !sourceIDs[id] {
+			result.errorf(fmt.Sprintf("keys.default[%d]", i), "undefined source %q", id)
+		}
+	}
+
+	for i, k := range p.Keys.Other {
+		field := fmt.Sprintf("keys.other[%d]", i)
+
+		validateSourceRefs(k.Sound, sourceIDs, field+".sound", result)
+
+		for j, name := range k.WhenModifiers {
+			if _, ok := key.FindModifier(name); !ok {
+				result.warnf(fmt.Sprintf("%s.when_modifiers[%d]", field, j), "unknown modifier %q", name)
+			}
+		}
+
+		if k.Keys == nil || len(*k.Keys) == 0 {
+			if len(k.WhenModifiers) == 0 {
+				result.warnf(field+".keys", "no keys listed")
+			}
+			continue
+		}
+
+		for j, name := range *k.Keys {
+			if key.IsSelector(name) {
+				if _, err := key.ParseSelector(name); err != nil {
+					result.warnf(fmt.Sprintf("%s.keys[%d]", field, j), "%v", err)
+				}
+				continue
+			}
+
+			if !isKnownKey(name) {
+				result.warnf(fmt.Sprintf("%s.keys[%d]", field, j), "unknown key %q", name)
+			}
+		}
+	}
+}
+
+// validateButtons validates the buttons section of a mouse profile.
+func validateButtons(p *Profile, sourceIDs map[string]bool, result *ValidationResult) {
+	for i, id := range p.Buttons.Default {
+		if !sourceIDs[id] {
+			result.errorf(fmt.Sprintf("buttons.default[%d]", i), "undefined source %q", id)
+		}
+	}
+
+	for i, b := range p.Buttons.Other {
+		field := fmt.Sprintf("buttons.other[%d]", i)
+
+		validateSourceRefs(b.Sound, sourceIDs, field+".sound", result)
+
+		if b.Buttons == nil || len(*b.Buttons) == 0 {
+			result.warnf(field+".buttons", "no buttons listed")
+			continue
+		}
+
+		for j, name := range *b.Buttons {
+			if !isKnownButton(name) {
+				result.warnf(fmt.Sprintf("%s.buttons[%d]", field, j), "unknown button %q", name)
+			}
+		}
+	}
+}
+
+// validateSourceRefs checks that every source ID referenced by a sound is defined.
+func validateSourceRefs(ids SoundRef, sourceIDs map[string]bool, field string, result *ValidationResult) {
+	if len(ids) == 0 {
+		result.errorf(field, "no sound source set")
+	}
+
+	for _, id := range ids {
+		if !sourceIDs[id] {
+			result.errorf(field, "undefined source %q", id)
+		}
+	}
+}
+
+// isKnownKey returns true if the name is a known key name or a numeric key code.
+func isKnownKey(name string) bool {
+	if _, ok := key.FindKey(name); ok {
+		return true
+	}
+
+	_, err := strconv.ParseUint(name, 10, 32)
+	return err == nil
+}
+
+// isKnownButton returns true if the name is a known mouse button.
+func isKnownButton(name string) bool {
+	return lo.ContainsBy([]listenertypes.Button{
+		listenertypes.ButtonLeft,
+		listenertypes.ButtonRight,
+		listenertypes.ButtonMiddle,
+	}, func(b listenertypes.Button) bool {
+		return strings.EqualFold(string(b), name)
+	})
+}
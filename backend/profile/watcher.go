@@ -0,0 +1,166 @@
+package profile
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// fileState represents the state of a file in the profiles directory at the time it was last polled.
+type fileState struct {
+	size    int64
+	modTime time.Time
+}
+
+// WatchProfiles polls the profiles directories of the default store for changes every interval until the
+// context is cancelled.
+//
+// When a file in a profiles directory is added, removed or modified, the profiles are reloaded with
+// LoadProfiles and onChange is called with the locations of the profiles that changed. The shared sample library
+// is watched as well, and a change to a sample counts as a change to every loaded profile that uses it.
+func WatchProfiles(ctx context.Context, interval time.Duration, onChange func(changed []string)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	previous := snapshotProfilesDir()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			current := snapshotProfilesDir()
+			changed := changedProfileLocations(previous, current)
+			previous = current
+
+			if len(changed) == 0 {
+				continue
+			}
+
+			slog.Info("Profiles changed on disk, reloading", "profiles", changed)
+			err := LoadProfiles()
+			if err != nil {
+				slog.Error("Failed to reload profiles", "error", err)
+				continue
+			}
+
+			onChange(changed)
+		}
+	}
+}
+
+// snapshotProfilesDir returns the state of every file in the profiles directories of the default store and in
+// the shared sample library, keyed by path. Layers stored in a file system other than the disk, such as embedded
+// profiles, are not watched.
+func snapshotProfilesDir() map[string]fileState {
+	dirs := lo.Map(diskLayers(), func(layer Layer, _ int) string {
+		return layer.Dir
+	})
+	if libraryDir := GetSampleLibraryDir(); libraryDir != "" {
+		dirs = append(dirs, libraryDir)
+	}
+
+	snapshot := make(map[string]fileState)
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				// Files can disappear while walking, skip them until the next poll.
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+
+			if d.IsDir() {
+				return nil
+			}
+
+			info, err := d.Info()
+			if err != nil {
+				return nil
+			}
+
+			snapshot[path] = fileState{
+				size:    info.Size(),
+				modTime: info.ModTime(),
+			}
+
+			return nil
+		})
+		if err != nil {
+			slog.Debug("Failed to poll profiles directory", "path", dir, "error", err)
+		}
+	}
+
+	return snapshot
+}
+
+// changedProfileLocations compares two snapshots of the profiles directories and returns the locations of the
+// profiles containing files that were added, removed or modified, and of the loaded profiles using shared samples
+// that were.
+func changedProfileLocations(previous map[string]fileState, current map[string]fileState) []string {
+	layers := diskLayers()
+	libraryDir := GetSampleLibraryDir()
+
+	changed := make([]string, 0)
+	for path, state := range current {
+		prev, ok := previous[path]
+		if !ok || prev.size != state.size || !prev.modTime.Equal(state.modTime) {
+			changed = append(changed, path)
+		}
+	}
+	for path := range previous {
+		if _, ok := current[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+
+	return lo.Uniq(lo.FlatMap(changed, func(path string, _ int) []string {
+		for _, layer := range layers {
+			rel, ok := relativePath(layer.Dir, path)
+			if !ok {
+				continue
+			}
+
+			profileDir, _, _ := strings.Cut(rel, "/")
+			return []string{filepath.Join(layer.Dir, profileDir)}
+		}
+
+		if sample, ok := relativePath(libraryDir, path); ok && libraryDir != "" {
+			return profilesUsingSample(sample)
+		}
+
+		return nil
+	}))
+}
+
+// relativePath returns the slash-separated path of a file relative to a directory, and whether the file is in
+// the directory.
+func relativePath(dir string, path string) (string, bool) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+
+	return filepath.ToSlash(rel), true
+}
+
+// profilesUsingSample returns the locations of the loaded profiles that use a sample in the shared sample
+// library, including the profiles that inherit sources using it.
+func profilesUsingSample(sample string) []string {
+	return lo.FilterMap(loadedProfiles(), func(p *Profile, _ int) (string, bool) {
+		return p.Location, lo.Contains(referencedSamples(p), sample)
+	})
+}
+
+// diskLayers returns the layers of the default store that are directories on disk.
+func diskLayers() []Layer {
+	return lo.Filter(defaultStore.Layers(), func(layer Layer, _ int) bool {
+		return layer.FS == nil
+	})
+}
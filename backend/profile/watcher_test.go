@@ -0,0 +1,82 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestChangedProfileLocationsIncludesSampleLibrary(t *testing.T) {
+	profilesDir := t.TempDir()
+	libraryDir := t.TempDir()
+
+	writeFile := func(path string, content string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile(filepath.Join(libraryDir, "thock", "press.wav"), "press")
+	writeFile(filepath.Join(profilesDir, "shared", "profile.yaml"), `
+profile:
+  name: Shared
+sources:
+  - id: press
+    source: lib:thock/press.wav
+keys:
+  default: [press]
+`)
+	writeFile(filepath.Join(profilesDir, "child", "profile.yaml"), `
+profile:
+  name: Child
+extends: shared
+`)
+	writeFile(filepath.Join(profilesDir, "own", "press.wav"), "own")
+	writeFile(filepath.Join(profilesDir, "own", "profile.yaml"), `
+profile:
+  name: Own
+sources:
+  - id: press
+    source: press.wav
+keys:
+  default: [press]
+`)
+
+	previousLayers := defaultStore.Layers()
+	previousLibraryDir := GetSampleLibraryDir()
+	t.Cleanup(func() {
+		SetSampleLibraryDir(previousLibraryDir)
+		defaultStore.SetLayers(previousLayers...)
+		defaultStore.Load()
+	})
+	SetSampleLibraryDir(libraryDir)
+	defaultStore.SetLayers(Layer{Name: "user", Dir: profilesDir})
+	if err := defaultStore.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	previous := snapshotProfilesDir()
+	samplePath := filepath.Join(libraryDir, "thock", "press.wav")
+	if _, ok := previous[samplePath]; !ok {
+		t.Fatalf("sample library was not polled")
+	}
+
+	writeFile(samplePath, "louder press")
+	modTime := time.Now().Add(time.Minute)
+	if err := os.Chtimes(samplePath, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+
+	changed := changedProfileLocations(previous, snapshotProfilesDir())
+	sort.Strings(changed)
+	want := []string{filepath.Join(profilesDir, "child"), filepath.Join(profilesDir, "shared")}
+	if !reflect.DeepEqual(changed, want) {
+		t.Errorf("changedProfileLocations() = %v, want %v", changed, want)
+	}
+}
@@ -52,9 +52,10 @@ type profilesDiff struct {
 
 // Profiles is a collection of profiles for mouse and keyboard.
 type Profiles struct {
-	// Keyboard is the profile to use for the keyboard.
+	// Keyboard is the ID of the profile to use for the keyboard. Older rules files may contain profile
+	// names instead, which are rewritten to IDs once profiles are loaded.
 	Keyboard *string `json:"keyboard"`
-	// Mouse is the profile to use for the mouse.
+	// Mouse is the ID of the profile to use for the mouse.
 	Mouse *string `json:"mouse"`
 	// IsDefault is whether the profiles are the default profiles.
 	IsDefault bool `json:"-"`
@@ -301,6 +302,59 @@ func RemoveRule(appPath string) error {
 	return nil
 }
 
+// RewriteProfileReferences replaces every profile reference in the default profiles and the rules with the
+// value returned by rewrite, and saves the rules if any reference changed.
+func RewriteProfileReferences(rewrite func(ref string) string) error {
+	rulesCacheLock.Lock()
+	defer rulesCacheLock.Unlock()
+
+	if rulesCache == nil {
+		return nil
+	}
+
+	changed := rulesCache.Default.RewriteReferences(rewrite)
+	for i := range rulesCache.Rules {
+		if rulesCache.Rules[i].Profiles.RewriteReferences(rewrite) {
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+
+	// Save the rules to the file.
+	rulesBytes, err := json.Marshal(rulesCache)
+	if err != nil {
+		return fmt.Errorf("failed to marshal rules: %w", err)
+	}
+
+	err = os.WriteFile(rulesCache.filePath, rulesBytes, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write rules file: %w", err)
+	}
+
+	return nil
+}
+
+// RewriteReferences replaces the profile references with the value returned by rewrite and reports whether
+// any of them changed.
+func (p *Profiles) RewriteReferences(rewrite func(ref string) string) bool {
+	changed := false
+	for _, ref := range []**string{&p.Keyboard, &p.Mouse} {
+		if *ref == nil {
+			continue
+		}
+
+		if newRef := rewrite(**ref); newRef != **ref {
+			*ref = &newRef
+			changed = true
+		}
+	}
+
+	return changed
+}
+
 // ListRules lists all rules.
 func ListRules() ([]Rule, error) {
 	rulesCacheLock.RLock()
@@ -49,8 +49,8 @@ func (a *AppRules) ListRules() ([]RuleData, error) {
 		result[i] = RuleData{
 			AppPath:         rule.AppPath,
 			ExecutableName:  filepath.Base(rule.AppPath),
-			KeyboardProfile: rule.Profiles.Keyboard,
-			MouseProfile:    rule.Profiles.Mouse,
+			KeyboardProfile: profileRefToName(rule.Profiles.Keyboard),
+			MouseProfile:    profileRefToName(rule.Profiles.Mouse),
 			Enabled:         rule.Enabled,
 		}
 	}
@@ -62,10 +62,10 @@ func (a *AppRules) ListRules() ([]RuleData, error) {
 func (a *AppRules) UpsertRule(appPath string, keyboardProfile *string, mouseProfile *string, enabled bool) error {
 	rule := rules.Rule{
 		AppPath: appPath,
-		Profiles: rules.Profiles{
-			Keyboard: keyboardProfile,
-			Mouse:    mouseProfile,
-		},
+		Profiles: kbsapp.FillDeskProfiles(rules.Profiles{
+			Keyboard: profileRefToID(keyboardProfile),
+			Mouse:    profileRefToID(mouseProfile),
+		}),
 		Enabled: enabled,
 	}
 
@@ -103,8 +103,10 @@ func (a *AppRules) UpdateRuleProfiles(appPath string, keyboardProfile *string, m
 
 	for _, rule := range rulesList {
 		if rule.AppPath == appPath {
-			rule.Profiles.Keyboard = keyboardProfile
-			rule.Profiles.Mouse = mouseProfile
+			rule.Profiles = kbsapp.FillDeskProfiles(rules.Profiles{
+				Keyboard: profileRefToID(keyboardProfile),
+				Mouse:    profileRefToID(mouseProfile),
+			})
 			return rules.UpsertRule(rule)
 		}
 	}
@@ -165,7 +167,7 @@ func (a *AppRules) BrowseForExecutable() (string, error) {
 // GetDefaultProfiles returns the default keyboard and mouse profiles
 // This is exposed here as well to keep the API consistent for the rules page
 func (a *AppRules) GetDefaultProfiles() rules.Profiles {
-	return rules.GetDefaultProfiles()
+	return profilesToNames(rules.GetDefaultProfiles())
 }
 
 // SetDefaultProfiles sets the default keyboard and mouse profiles
@@ -174,7 +176,7 @@ func (a *AppRules) SetDefaultProfiles(keyboard *string, mouse *string) error {
 		Keyboard: keyboard,
 		Mouse:    mouse,
 	}
-	return kbsApp.SetDefaultProfiles(profiles)
+	return kbsApp.SetDefaultProfiles(kbsapp.FillDeskProfiles(profiles))
 }
 
 // GetInfoBannerDismissed returns whether the info banner has been dismissed
@@ -282,14 +284,17 @@ func (a *AppRules) GetApplicationRulesHeroContext() kbsapp.ApplicationRulesHeroC
 	if kbsApp == nil {
 		return kbsapp.ApplicationRulesHeroContext{Supported: false}
 	}
-	return kbsApp.GetApplicationRulesHeroContext()
+	hero := kbsApp.GetApplicationRulesHeroContext()
+	hero.KeyboardProfile = profileRefToName(hero.KeyboardProfile)
+	hero.MouseProfile = profileRefToName(hero.MouseProfile)
+	return hero
 }
 
 // GetInAppFocusProfiles returns persisted in-app (self-focused) profile overrides.
 func (a *AppRules) GetInAppFocusProfiles() InAppFocusProfileSettings {
 	return InAppFocusProfileSettings{
-		KeyboardProfile: GetInAppKeyboardProfile(),
-		MouseProfile:    GetInAppMouseProfile(),
+		KeyboardProfile: profileRefToName(GetInAppKeyboardProfile()),
+		MouseProfile:    profileRefToName(GetInAppMouseProfile()),
 	}
 }
 
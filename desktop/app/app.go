@@ -8,7 +8,6 @@ import (
 	"path/filepath"
 
 	"github.com/emersion/go-autostart"
-	"github.com/otiai10/copy"
 	"github.com/wailsapp/wails/v2/pkg/options"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 
@@ -16,6 +15,7 @@ import (
 
 	kbs "github.com/keyboard-sounds/keyboardsounds-pro/backend"
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/app"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
 )
 
 var (
@@ -53,13 +53,22 @@ func Init(c context.Context) error {
 	// Register delegate for OSK helper state changes
 	app.RegisterOSKHelperStateChangedDelegate(EmitOSKHelperStateChanged)
 
+	// Register delegate to emit events when profiles change on disk
+	RegisterProfilesChangedEventDelegate()
+
 	// Apply saved audio effects preferences
 	ApplyAudioEffectsFromPreferences()
 	// Apply saved volume preferences
 	ApplyVolumeFromPreferences()
+	// Apply saved key repeat preferences
+	ApplyKeyRepeatFromPreferences()
 	// Apply saved OSK Helper preferences
 	ApplyOSKHelperFromPreferences()
 	ApplyInAppFocusProfilesFromPreferences()
+	// Apply saved profile blend preferences
+	ApplyProfileBlendFromPreferences()
+	// Apply saved profile rotation preferences
+	ApplyProfileRotationFromPreferences()
 	registerOSKOverlayClickHandler()
 	// Enable application if start playing on launch is set
 	if GetStartPlayingOnLaunch() {
@@ -87,6 +96,13 @@ func Init(c context.Context) error {
 	return nil
 }
 
+// Shutdown closes the application, stopping playback and the background work it started.
+func Shutdown() {
+	if kbsApp != nil {
+		kbsApp.Close()
+	}
+}
+
 func GetAutoStartApp() *autostart.App {
 	return autoStartApp
 }
@@ -97,65 +113,55 @@ func OnSecondInstanceLaunched(data options.SecondInstanceData) {
 }
 
 func seedProfiles() error {
-	exePath, err := os.Executable()
-	if err != nil {
-		panic(err)
-	}
-
-	installDir := filepath.Dir(exePath)
-
 	var (
-		bundledProfilesDir string = filepath.Join(installDir, "bundled-profiles")
-		kbsProfilesDir     string = filepath.Join(kbsDir, "profiles")
+		bundledDir     string = bundledProfilesDir()
+		kbsProfilesDir string = filepath.Join(kbsDir, "profiles")
 	)
 
-	if gort.GOOS == "darwin" {
-		bundledProfilesDir = filepath.Join(installDir, "..", "Resources", "bundled-profiles")
-	}
-
-	slog.Info("Seeding profiles", "bundledProfilesDir", bundledProfilesDir, "kbsProfilesDir", kbsProfilesDir)
+	slog.Info("Seeding profiles", "bundledProfilesDir", bundledDir, "kbsProfilesDir", kbsProfilesDir)
 
 	// Ensure bundled profiles dir exists
-	if _, err := os.Stat(bundledProfilesDir); os.IsNotExist(err) {
+	if _, err := os.Stat(bundledDir); os.IsNotExist(err) {
 		return fmt.Errorf("bundled profiles dir does not exist")
 	}
 
-	// Check if kbs profiles dir exists, and if not, create it
-	if _, err := os.Stat(kbsProfilesDir); os.IsNotExist(err) {
-		// Create kbs profiles dir
-		err = os.MkdirAll(kbsProfilesDir, 0755)
-		if err != nil {
-			return fmt.Errorf("failed to create kbs profiles dir: %w", err)
-		}
+	// Keep kbs profiles directory in sync with bundled profiles directory. New bundled profiles are installed
+	// and bundled profiles that the user has not modified are updated with a new release. Profiles the user
+	// has modified or deleted are left alone.
+	report, err := profile.SyncBundledProfiles(bundledDir, kbsProfilesDir, bundledProfilesStatePath())
+	if err != nil {
+		return fmt.Errorf("failed to sync bundled profiles: %w", err)
+	}
 
-		// Copy all contents of bundled profiles dir to kbs profiles dir
-		err = copy.Copy(bundledProfilesDir, kbsProfilesDir)
-		if err != nil {
-			return fmt.Errorf("failed to copy bundled profiles to kbs profiles dir: %w", err)
-		}
+	slog.Info("Synced bundled profiles",
+		"installed", len(report.Installed),
+		"updated", len(report.Updated),
+		"kept", len(report.Kept),
+		"skipped", len(report.Skipped))
 
-		return nil
-	}
+	bundledProfilesReportLock.Lock()
+	bundledProfilesReport = report
+	bundledProfilesReportLock.Unlock()
+
+	return nil
+}
 
-	// Keep kbs profiles directory in sync with bundled profiles directory. This way if new profiles are added
-	// to the bundled profiles directory with a new release, the profiles will be available in the kbs profiles directory.
-	bundled, err := os.ReadDir(bundledProfilesDir)
+// bundledProfilesDir returns the directory that the bundled profiles are installed in
+func bundledProfilesDir() string {
+	exePath, err := os.Executable()
 	if err != nil {
-		return fmt.Errorf("failed to read bundled profiles dir: %w", err)
+		panic(err)
 	}
 
-	for _, profile := range bundled {
-		if profile.IsDir() {
-			id := profile.Name()
-			profilePath := filepath.Join(kbsProfilesDir, id)
-			if _, err := os.Stat(profilePath); os.IsNotExist(err) {
-				err = copy.Copy(filepath.Join(bundledProfilesDir, id), profilePath)
-				if err != nil {
-					return fmt.Errorf("failed to copy bundled profile %s to kbs profiles dir: %w", id, err)
-				}
-			}
-		}
+	installDir := filepath.Dir(exePath)
+	if gort.GOOS == "darwin" {
+		return filepath.Join(installDir, "..", "Resources", "bundled-profiles")
 	}
 
-	return nil
+	return filepath.Join(installDir, "bundled-profiles")
+}
+
+// bundledProfilesStatePath returns the path of the file recording the installed bundled profiles
+func bundledProfilesStatePath() string {
+	return filepath.Join(kbsDir, "bundled-profiles.json")
 }
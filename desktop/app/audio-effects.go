@@ -141,3 +141,70 @@ func (a *AudioEffects) SetMouseEqualizer(enabled bool, config audio.EqualizerCon
 	kbsApp.SetMouseAudioEqualizer(enabled, config)
 	return SaveAudioEffectsToPreferences()
 }
+
+// KeyRepeatState represents the current key repeat settings
+type KeyRepeatState struct {
+	Mode    string  `json:"mode"`
+	MaxRate float64 `json:"maxRate"`
+}
+
+// GetKeyRepeat returns how sounds are played while a key is held down
+func (a *AudioEffects) GetKeyRepeat() KeyRepeatState {
+	mode, maxRate := kbsApp.GetKeyRepeat()
+	return KeyRepeatState{
+		Mode:    string(mode),
+		MaxRate: maxRate,
+	}
+}
+
+// SetKeyRepeat sets how sounds are played while a key is held down
+func (a *AudioEffects) SetKeyRepeat(mode string, maxRate float64) error {
+	kbsApp.SetKeyRepeat(app.KeyRepeatMode(mode), maxRate)
+	return SaveKeyRepeatToPreferences()
+}
+
+// GetKeyboardBlend returns the profiles mixed with the active keyboard profile, referring to them by name
+func (a *AudioEffects) GetKeyboardBlend() app.ProfileBlend {
+	return blendToNames(kbsApp.GetKeyboardBlend())
+}
+
+// SetKeyboardBlend sets the profiles mixed with the active keyboard profile
+func (a *AudioEffects) SetKeyboardBlend(blend app.ProfileBlend) error {
+	if err := kbsApp.SetKeyboardBlend(blendToIDs(blend)); err != nil {
+		return err
+	}
+	return SaveProfileBlendToPreferences()
+}
+
+// GetMouseBlend returns the profiles mixed with the active mouse profile, referring to them by name
+func (a *AudioEffects) GetMouseBlend() app.ProfileBlend {
+	return blendToNames(kbsApp.GetMouseBlend())
+}
+
+// SetMouseBlend sets the profiles mixed with the active mouse profile
+func (a *AudioEffects) SetMouseBlend(blend app.ProfileBlend) error {
+	if err := kbsApp.SetMouseBlend(blendToIDs(blend)); err != nil {
+		return err
+	}
+	return SaveProfileBlendToPreferences()
+}
+
+// blendToNames returns a copy of blend with the layers referring to their profiles by name
+func blendToNames(blend app.ProfileBlend) app.ProfileBlend {
+	layers := make([]app.BlendLayer, len(blend.Layers))
+	for i, layer := range blend.Layers {
+		layers[i] = app.BlendLayer{Profile: *profileRefToName(&layer.Profile), Weight: layer.Weight}
+	}
+	blend.Layers = layers
+	return blend
+}
+
+// blendToIDs returns a copy of blend with the layers referring to their profiles by ID
+func blendToIDs(blend app.ProfileBlend) app.ProfileBlend {
+	layers := make([]app.BlendLayer, len(blend.Layers))
+	for i, layer := range blend.Layers {
+		layers[i] = app.BlendLayer{Profile: *profileRefToID(&layer.Profile), Weight: layer.Weight}
+	}
+	blend.Layers = layers
+	return blend
+}
@@ -0,0 +1,69 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+)
+
+var (
+	// The report of the bundled profiles synced when the application started
+	bundledProfilesReport     profile.BundledSyncReport
+	bundledProfilesReportLock sync.RWMutex
+)
+
+// GetBundledProfilesReport returns the bundled profiles that were installed or updated when the application
+// started, and those that have an update that was not applied because the user modified them
+func (l *Library) GetBundledProfilesReport() profile.BundledSyncReport {
+	bundledProfilesReportLock.RLock()
+	defer bundledProfilesReportLock.RUnlock()
+
+	report := bundledProfilesReport
+	if report.Installed == nil {
+		report = profile.BundledSyncReport{
+			Installed: []profile.BundledProfileChange{},
+			Updated:   []profile.BundledProfileChange{},
+			Kept:      []profile.BundledProfileChange{},
+			Skipped:   []profile.BundledProfileChange{},
+		}
+	}
+
+	return report
+}
+
+// DismissBundledProfilesReport clears the bundled profiles report so that it is not shown again
+func (l *Library) DismissBundledProfilesReport() {
+	bundledProfilesReportLock.Lock()
+	defer bundledProfilesReportLock.Unlock()
+
+	bundledProfilesReport = profile.BundledSyncReport{}
+}
+
+// RestoreBundledProfile replaces a bundled profile with the version bundled with this release, discarding the
+// changes the user made to it
+func (l *Library) RestoreBundledProfile(id string) error {
+	err := kbsApp.RestoreBundledProfile(bundledProfilesDir(), bundledProfilesStatePath(), id)
+	if err != nil {
+		return fmt.Errorf("failed to restore bundled profile: %w", err)
+	}
+
+	bundledProfilesReportLock.Lock()
+	defer bundledProfilesReportLock.Unlock()
+
+	bundledProfilesReport.Kept = removeBundledProfileChange(bundledProfilesReport.Kept, id)
+	bundledProfilesReport.Skipped = removeBundledProfileChange(bundledProfilesReport.Skipped, id)
+	return nil
+}
+
+// removeBundledProfileChange returns the changes without the change for the given profile
+func removeBundledProfileChange(changes []profile.BundledProfileChange, id string) []profile.BundledProfileChange {
+	remaining := make([]profile.BundledProfileChange, 0, len(changes))
+	for _, change := range changes {
+		if change.ID != id {
+			remaining = append(remaining, change)
+		}
+	}
+
+	return remaining
+}
@@ -3,6 +3,7 @@ package app
 import (
 	"fmt"
 	"os/exec"
+	"path/filepath"
 	goRuntime "runtime"
 	"strings"
 
@@ -21,74 +22,185 @@ func NewLibrary() *Library {
 
 // ProfileData represents a profile for the frontend
 type ProfileData struct {
-	ID          string `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	Author      string `json:"author"`
-	Type        string `json:"type"`
-	InUse       bool   `json:"inUse"`
-	InUseReason string `json:"inUseReason"`
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// DisplayName is the name translated to the profile locale. Name is the canonical name, which profiles are
+	// referred to by.
+	DisplayName string   `json:"displayName"`
+	Description string   `json:"description"`
+	Author      string   `json:"author"`
+	Type        string   `json:"type"`
+	Tags        []string `json:"tags"`
+	SwitchType  string   `json:"switchType"`
+	License     string   `json:"license"`
+	Version     string   `json:"version"`
+	Homepage    string   `json:"homepage"`
+	Preview     string   `json:"preview"`
+	InUse       bool     `json:"inUse"`
+	InUseReason string   `json:"inUseReason"`
+	// ReadOnly is true for profiles installed in a read-only system-wide directory, which cannot be removed
+	// or edited
+	ReadOnly bool     `json:"readOnly"`
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
 }
 
 // LibraryState represents the state of the library page
 type LibraryState struct {
 	KeyboardProfiles []ProfileData `json:"keyboardProfiles"`
 	MouseProfiles    []ProfileData `json:"mouseProfiles"`
+	// DeskProfiles are the profiles for both the keyboard and the mouse. They are not included in
+	// KeyboardProfiles or MouseProfiles.
+	DeskProfiles []ProfileData `json:"deskProfiles"`
+}
+
+// LibrarySearchFilters represents the filters applied to a library search
+type LibrarySearchFilters struct {
+	// Type is "keyboard", "mouse", "desk", or empty for all profiles
+	Type string `json:"type"`
+	// Tags are the tags that every result must have
+	Tags []string `json:"tags"`
+	// Author is the author that every result must have
+	Author string `json:"author"`
+	// SortBy is "relevance", "name", "author" or "type"
+	SortBy string `json:"sortBy"`
 }
 
 // GetState returns the current state of the library
 func (l *Library) GetState() LibraryState {
 	keyboardProfiles := make([]ProfileData, 0)
+	deskProfiles := make([]ProfileData, 0)
 	for _, p := range profile.GetKeyboardProfiles() {
-		inUse, reason := l.checkProfileInUse(p.Details.Name, "keyboard")
-		keyboardProfiles = append(keyboardProfiles, ProfileData{
-			ID:          p.Details.Name,
-			Name:        p.Details.Name,
-			Description: p.Details.Description,
-			Author:      p.Details.Author,
-			Type:        "keyboard",
-			InUse:       inUse,
-			InUseReason: reason,
-		})
+		if p.Details.DeviceType == profile.DeviceTypeDesk {
+			deskProfiles = append(deskProfiles, l.profileData(p))
+			continue
+		}
+		keyboardProfiles = append(keyboardProfiles, l.profileData(p))
 	}
 
 	mouseProfiles := make([]ProfileData, 0)
 	for _, p := range profile.GetMouseProfiles() {
-		inUse, reason := l.checkProfileInUse(p.Details.Name, "mouse")
-		mouseProfiles = append(mouseProfiles, ProfileData{
-			ID:          p.Details.Name,
-			Name:        p.Details.Name,
-			Description: p.Details.Description,
-			Author:      p.Details.Author,
-			Type:        "mouse",
-			InUse:       inUse,
-			InUseReason: reason,
-		})
+		if p.Details.DeviceType == profile.DeviceTypeDesk {
+			continue
+		}
+		mouseProfiles = append(mouseProfiles, l.profileData(p))
 	}
 
 	return LibraryState{
 		KeyboardProfiles: keyboardProfiles,
 		MouseProfiles:    mouseProfiles,
+		DeskProfiles:     deskProfiles,
+	}
+}
+
+// Search returns the profiles matching the query and filters, in the requested order
+func (l *Library) Search(query string, filters LibrarySearchFilters) []ProfileData {
+	sortBy := profile.SortOrder(filters.SortBy)
+	if filters.SortBy == "type" {
+		sortBy = profile.SortByDevice
+	}
+
+	results := profile.Search(query, profile.SearchFilters{
+		Tags:       filters.Tags,
+		Author:     filters.Author,
+		DeviceType: profile.DeviceType(filters.Type),
+		Locale:     profileLocale(),
+		SortBy:     sortBy,
+	})
+
+	data := make([]ProfileData, 0, len(results))
+	for _, p := range results {
+		data = append(data, l.profileData(p))
+	}
+
+	return data
+}
+
+// ListTags returns every tag used by the profiles in the library
+func (l *Library) ListTags() []string {
+	return profile.ListTags()
+}
+
+// profileData converts a profile to its frontend representation
+func (l *Library) profileData(p *profile.Profile) ProfileData {
+	profileType := string(p.Details.DeviceType)
+	inUse, reason := l.checkProfileInUse(p)
+	locale := profileLocale()
+
+	tags := p.Details.Tags
+	if tags == nil {
+		tags = []string{}
+	}
+
+	return ProfileData{
+		ID:          p.ID,
+		Name:        p.Details.Name,
+		DisplayName: p.LocalizedName(locale),
+		Description: p.LocalizedDescription(locale),
+		Author:      p.Details.Author,
+		Type:        profileType,
+		Tags:        tags,
+		SwitchType:  p.Details.SwitchType,
+		License:     p.Details.License,
+		Version:     p.Details.Version,
+		Homepage:    p.Details.Homepage,
+		Preview:     p.PreviewPath(),
+		InUse:       inUse,
+		InUseReason: reason,
+		ReadOnly:    p.ReadOnly,
+		Errors:      diagnosticMessages(p.Validation.Errors),
+		Warnings:    diagnosticMessages(p.Validation.Warnings),
+	}
+}
+
+// ProfileValidationData represents the result of fully validating a profile for the frontend
+type ProfileValidationData struct {
+	Errors   []string `json:"errors"`
+	Warnings []string `json:"warnings"`
+}
+
+// ValidateProfile fully validates a profile, including decoding its audio files. The errors and warnings of
+// ProfileData only cover the structure of the profile, which is all that is checked when profiles are loaded.
+func (l *Library) ValidateProfile(ref string) (ProfileValidationData, error) {
+	p, found := profile.FindProfile(ref)
+	if !found {
+		return ProfileValidationData{}, fmt.Errorf("profile not found")
+	}
+
+	result := p.FullValidation()
+	return ProfileValidationData{
+		Errors:   diagnosticMessages(result.Errors),
+		Warnings: diagnosticMessages(result.Warnings),
+	}, nil
+}
+
+// diagnosticMessages converts validation diagnostics to messages for the frontend
+func diagnosticMessages(diagnostics []profile.Diagnostic) []string {
+	messages := make([]string, 0, len(diagnostics))
+	for _, d := range diagnostics {
+		messages = append(messages, d.String())
 	}
+	return messages
 }
 
-// checkProfileInUse checks if a profile is currently in use by default profiles or app rules
-func (l *Library) checkProfileInUse(profileName string, profileType string) (bool, string) {
+// checkProfileInUse checks if a profile is currently in use by default profiles or app rules. Desk profiles are
+// checked against both the keyboard and mouse profiles.
+func (l *Library) checkProfileInUse(p *profile.Profile) (bool, string) {
 	reasons := make([]string, 0)
 
 	// Check if it's the default profile
 	defaultProfiles := rules.GetDefaultProfiles()
-	if profileType == "keyboard" && defaultProfiles.Keyboard != nil && strings.EqualFold(*defaultProfiles.Keyboard, profileName) {
+	if p.SupportsKeyboard() && profileRefMatches(defaultProfiles.Keyboard, p) {
 		reasons = append(reasons, "default keyboard profile")
 	}
-	if profileType == "mouse" && defaultProfiles.Mouse != nil && strings.EqualFold(*defaultProfiles.Mouse, profileName) {
+	if p.SupportsMouse() && profileRefMatches(defaultProfiles.Mouse, p) {
 		reasons = append(reasons, "default mouse profile")
 	}
 
-	if kb := GetInAppKeyboardProfile(); kb != nil && profileType == "keyboard" && strings.EqualFold(*kb, profileName) {
+	if p.SupportsKeyboard() && profileRefMatches(GetInAppKeyboardProfile(), p) {
 		reasons = append(reasons, "in-app keyboard profile (Settings)")
 	}
-	if ms := GetInAppMouseProfile(); ms != nil && profileType == "mouse" && strings.EqualFold(*ms, profileName) {
+	if p.SupportsMouse() && profileRefMatches(GetInAppMouseProfile(), p) {
 		reasons = append(reasons, "in-app mouse profile (Settings)")
 	}
 
@@ -96,11 +208,11 @@ func (l *Library) checkProfileInUse(profileName string, profileType string) (boo
 	rulesList, err := rules.ListRules()
 	if err == nil {
 		for _, rule := range rulesList {
-			if profileType == "keyboard" && rule.Profiles.Keyboard != nil && strings.EqualFold(*rule.Profiles.Keyboard, profileName) {
+			if p.SupportsKeyboard() && profileRefMatches(rule.Profiles.Keyboard, p) {
 				reasons = append(reasons, "application rule")
 				break
 			}
-			if profileType == "mouse" && rule.Profiles.Mouse != nil && strings.EqualFold(*rule.Profiles.Mouse, profileName) {
+			if p.SupportsMouse() && profileRefMatches(rule.Profiles.Mouse, p) {
 				reasons = append(reasons, "application rule")
 				break
 			}
@@ -114,45 +226,195 @@ func (l *Library) checkProfileInUse(profileName string, profileType string) (boo
 	return true, "Used by: " + strings.Join(reasons, ", ")
 }
 
-// DeleteProfile deletes a profile by name
-func (l *Library) DeleteProfile(name string) error {
-	// First check if the profile exists and get its type
-	keyboardProfile, foundKeyboard := findKeyboardProfile(name)
-	mouseProfile, foundMouse := findMouseProfile(name)
-
-	if !foundKeyboard && !foundMouse {
-		return fmt.Errorf("profile not found")
+// DeleteProfile deletes a profile by ID or name
+func (l *Library) DeleteProfile(ref string) error {
+	// First check if the profile exists
+	p, found := findKeyboardProfile(ref)
+	if !found {
+		p, found = findMouseProfile(ref)
 	}
-
-	profileType := "keyboard"
-	if foundMouse {
-		profileType = "mouse"
-		_ = mouseProfile
-	} else {
-		_ = keyboardProfile
+	if !found {
+		return fmt.Errorf("profile not found")
 	}
 
 	// Check if it's in use
-	inUse, reason := l.checkProfileInUse(name, profileType)
+	inUse, reason := l.checkProfileInUse(p)
 	if inUse {
 		return fmt.Errorf("cannot delete profile: %s", reason)
 	}
 
-	return profile.DeleteProfile(name)
+	return profile.DeleteProfile(p.ID)
+}
+
+// RenameProfile renames a profile. Rules and preferences that refer to the profile keep working.
+func (l *Library) RenameProfile(ref string, newName string) error {
+	p, found := profile.FindProfile(ref)
+	if !found {
+		return fmt.Errorf("profile not found")
+	}
+	oldName := p.Details.Name
+
+	renamed, err := kbsApp.RenameProfile(ref, newName)
+	if err != nil {
+		return fmt.Errorf("failed to rename profile: %w", err)
+	}
+
+	err = rewriteInAppFocusProfileReferences(func(r string) string {
+		if strings.EqualFold(r, oldName) {
+			return renamed.ID
+		}
+		return r
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update preferences for renamed profile: %w", err)
+	}
+
+	return nil
+}
+
+// DuplicateProfile copies a profile to a new profile with the given name and returns the ID of the copy
+func (l *Library) DuplicateProfile(ref string, newName string) (string, error) {
+	duplicate, err := kbsApp.DuplicateProfile(ref, newName)
+	if err != nil {
+		return "", fmt.Errorf("failed to duplicate profile: %w", err)
+	}
+
+	return duplicate.ID, nil
 }
 
-func findKeyboardProfile(name string) (*profile.Profile, bool) {
+// ProfileDiskUsage represents the disk usage of a profile for the frontend
+type ProfileDiskUsage struct {
+	ID            string            `json:"id"`
+	Name          string            `json:"name"`
+	Location      string            `json:"location"`
+	TotalBytes    int64             `json:"totalBytes"`
+	FileCount     int               `json:"fileCount"`
+	OrphanedFiles []OrphanedFile    `json:"orphanedFiles"`
+	OrphanedBytes int64             `json:"orphanedBytes"`
+	MissingFiles  []MissingFileData `json:"missingFiles"`
+}
+
+// OrphanedFile represents an audio file in a profile folder that no source references
+type OrphanedFile struct {
+	Path string `json:"path"`
+	Size int64  `json:"size"`
+}
+
+// MissingFileData represents an audio file referenced by a source that does not exist
+type MissingFileData struct {
+	Path     string `json:"path"`
+	SourceID string `json:"sourceId"`
+}
+
+// CleanupData represents the files removed by a cleanup
+type CleanupData struct {
+	Removed      []string `json:"removed"`
+	RemovedBytes int64    `json:"removedBytes"`
+}
+
+// GetDiskUsage returns the disk usage of every profile in the library, from largest to smallest
+func (l *Library) GetDiskUsage() ([]ProfileDiskUsage, error) {
+	usages, err := profile.GetDiskUsage()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get disk usage: %w", err)
+	}
+
+	data := make([]ProfileDiskUsage, 0, len(usages))
+	for _, usage := range usages {
+		data = append(data, diskUsageData(usage))
+	}
+
+	return data, nil
+}
+
+// CleanupProfile removes the audio files in a profile folder that no source references
+func (l *Library) CleanupProfile(ref string) (CleanupData, error) {
+	result, err := profile.RemoveOrphanedFiles(ref)
+	if err != nil {
+		return CleanupData{}, fmt.Errorf("failed to clean up profile: %w", err)
+	}
+
+	return CleanupData{Removed: result.Removed, RemovedBytes: result.RemovedBytes}, nil
+}
+
+// CleanupAllProfiles removes the audio files that no source references from every profile folder
+func (l *Library) CleanupAllProfiles() (CleanupData, error) {
+	usages, err := profile.GetDiskUsage()
+	if err != nil {
+		return CleanupData{}, fmt.Errorf("failed to get disk usage: %w", err)
+	}
+
+	data := CleanupData{Removed: make([]string, 0)}
+	for _, usage := range usages {
+		if len(usage.OrphanedFiles) == 0 {
+			continue
+		}
+
+		result, err := profile.RemoveOrphanedFiles(usage.ProfileID)
+		if err != nil {
+			return data, fmt.Errorf("failed to clean up profile: %w", err)
+		}
+
+		for _, removed := range result.Removed {
+			data.Removed = append(data.Removed, filepath.Join(filepath.Base(usage.Location), removed))
+		}
+		data.RemovedBytes += result.RemovedBytes
+	}
+
+	return data, nil
+}
+
+// diskUsageData converts the disk usage of a profile to its frontend representation
+func diskUsageData(usage profile.DiskUsage) ProfileDiskUsage {
+	name := usage.ProfileID
+	if p, ok := profile.FindProfileByID(usage.ProfileID); ok {
+		name = p.Details.Name
+	}
+
+	orphaned := make([]OrphanedFile, 0, len(usage.OrphanedFiles))
+	for _, file := range usage.OrphanedFiles {
+		orphaned = append(orphaned, OrphanedFile{Path: file.Path, Size: file.Size})
+	}
+
+	missing := make([]MissingFileData, 0, len(usage.MissingFiles))
+	for _, file := range usage.MissingFiles {
+		missing = append(missing, MissingFileData{Path: file.Path, SourceID: file.SourceID})
+	}
+
+	return ProfileDiskUsage{
+		ID:            usage.ProfileID,
+		Name:          name,
+		Location:      usage.Location,
+		TotalBytes:    usage.TotalBytes,
+		FileCount:     usage.FileCount,
+		OrphanedFiles: orphaned,
+		OrphanedBytes: usage.OrphanedBytes,
+		MissingFiles:  missing,
+	}
+}
+
+func findKeyboardProfile(ref string) (*profile.Profile, bool) {
+	for _, p := range profile.GetKeyboardProfiles() {
+		if p.ID == ref {
+			return p, true
+		}
+	}
 	for _, p := range profile.GetKeyboardProfiles() {
-		if strings.EqualFold(p.Details.Name, name) {
+		if strings.EqualFold(p.Details.Name, ref) {
 			return p, true
 		}
 	}
 	return nil, false
 }
 
-func findMouseProfile(name string) (*profile.Profile, bool) {
+func findMouseProfile(ref string) (*profile.Profile, bool) {
+	for _, p := range profile.GetMouseProfiles() {
+		if p.ID == ref {
+			return p, true
+		}
+	}
 	for _, p := range profile.GetMouseProfiles() {
-		if strings.EqualFold(p.Details.Name, name) {
+		if strings.EqualFold(p.Details.Name, ref) {
 			return p, true
 		}
 	}
@@ -186,8 +448,9 @@ func (l *Library) OpenProfileFolder(name string) error {
 	return cmd.Start()
 }
 
-// ImportProfile opens a file dialog to select a zip file and imports the profile
-func (l *Library) ImportProfile() error {
+// ImportProfile opens a file dialog to select a zip file and imports the profile. It returns nil if the user
+// cancelled the dialog.
+func (l *Library) ImportProfile() (*profile.ImportResult, error) {
 	selection, err := runtime.OpenFileDialog(ctx, runtime.OpenDialogOptions{
 		Title: "Import Profile",
 		Filters: []runtime.FileFilter{
@@ -202,21 +465,26 @@ func (l *Library) ImportProfile() error {
 		},
 	})
 	if err != nil {
-		return fmt.Errorf("failed to open file dialog: %w", err)
+		return nil, fmt.Errorf("failed to open file dialog: %w", err)
 	}
 
 	if selection == "" {
 		// User cancelled the dialog
-		return nil
+		return nil, nil
+	}
+
+	err = applyTrustedFingerprints()
+	if err != nil {
+		return nil, err
 	}
 
 	// Import the profile
-	err = profile.ImportProfile(selection)
+	result, err := profile.ImportProfile(selection)
 	if err != nil {
-		return fmt.Errorf("failed to import profile: %w", err)
+		return nil, fmt.Errorf("failed to import profile: %w", err)
 	}
 
-	return nil
+	return result, nil
 }
 
 // ExportProfile opens a save dialog to select where to save the zip file and exports the profile
@@ -254,8 +522,12 @@ func (l *Library) ExportProfile(name string) error {
 		return nil
 	}
 
-	// Export the profile
-	err = profile.ExportProfile(name, selection)
+	// Export the profile, signing it if a signer is configured
+	options, err := exportOptions()
+	if err != nil {
+		return fmt.Errorf("failed to load signing key: %w", err)
+	}
+	err = profile.ExportProfileWithOptions(p.ID, selection, options)
 	if err != nil {
 		return fmt.Errorf("failed to export profile: %w", err)
 	}
@@ -262,6 +262,15 @@ func (pb *ProfileBuilder) BuildKeyboardProfile(request KeyboardBuildRequest) err
 		return fmt.Errorf("failed to write profile.yaml: %w", err)
 	}
 
+	// Validate the profile before adding it to the library
+	builtProfile, err := profile.LoadProfile(tempDir)
+	if err != nil {
+		return fmt.Errorf("failed to load built profile: %w", err)
+	}
+	if err = profile.Validate(builtProfile).Err(); err != nil {
+		return err
+	}
+
 	// Generate UUID for the profile directory name
 	profileUUID := uuid.New().String()
 
@@ -349,6 +358,15 @@ func (pb *ProfileBuilder) BuildMouseProfile(request MouseBuildRequest) error {
 		return fmt.Errorf("failed to write profile.yaml: %w", err)
 	}
 
+	// Validate the profile before adding it to the library
+	builtProfile, err := profile.LoadProfile(tempDir)
+	if err != nil {
+		return fmt.Errorf("failed to load built profile: %w", err)
+	}
+	if err = profile.Validate(builtProfile).Err(); err != nil {
+		return err
+	}
+
 	// Generate UUID for the profile directory name
 	profileUUID := uuid.New().String()
 
@@ -427,6 +445,7 @@ func buildKeyboardProfileYAML(request KeyboardBuildRequest) map[string]any {
 	}
 
 	return map[string]any{
+		"schema_version": profile.CurrentSchemaVersion,
 		"profile": map[string]any{
 			"name":        request.Metadata.Name,
 			"author":      request.Metadata.Author,
@@ -445,7 +464,7 @@ func buildKeyboardProfileYAML(request KeyboardBuildRequest) map[string]any {
 func buildMouseProfileYAML(request MouseBuildRequest) map[string]any {
 	// Build sources
 	sources := make([]map[string]any, 0, len(request.Sources))
-	var defaultSource string
+	defaultSources := make([]string, 0)
 
 	for _, src := range request.Sources {
 		sourceConfig := map[string]any{
@@ -461,13 +480,13 @@ func buildMouseProfileYAML(request MouseBuildRequest) map[string]any {
 		})
 
 		if src.IsDefault {
-			defaultSource = src.Name
+			defaultSources = append(defaultSources, src.Name)
 		}
 	}
 
 	// Check if there's a default assignment in mouseAssignments
 	if defaultAssignment, ok := request.MouseAssignments["default"]; ok && defaultAssignment != "" {
-		defaultSource = defaultAssignment
+		defaultSources = []string{defaultAssignment}
 	}
 
 	// Build button assignments (other section)
@@ -483,6 +502,7 @@ func buildMouseProfileYAML(request MouseBuildRequest) map[string]any {
 	}
 
 	return map[string]any{
+		"schema_version": profile.CurrentSchemaVersion,
 		"profile": map[string]any{
 			"name":        request.Metadata.Name,
 			"author":      request.Metadata.Author,
@@ -491,7 +511,7 @@ func buildMouseProfileYAML(request MouseBuildRequest) map[string]any {
 		},
 		"sources": sources,
 		"buttons": map[string]any{
-			"default": defaultSource,
+			"default": defaultSources,
 			"other":   other,
 		},
 	}
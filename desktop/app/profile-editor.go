@@ -0,0 +1,237 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+)
+
+// EditableSource represents a source of a profile being edited
+type EditableSource struct {
+	ID          string  `json:"id"`
+	Press       *string `json:"press"`
+	Release     *string `json:"release"`
+	Repeat      *string `json:"repeat"`
+	Hold        *string `json:"hold"`
+	ReleaseLong *string `json:"releaseLong"`
+}
+
+// EditableProfile represents a profile being edited, as written in its profile.yaml
+type EditableProfile struct {
+	ID          string           `json:"id"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Author      string           `json:"author"`
+	Type        string           `json:"type"`
+	Extends     string           `json:"extends"`
+	Sources     []EditableSource `json:"sources"`
+	// DefaultKeySources are the sources played for keys that are not assigned
+	DefaultKeySources []string `json:"defaultKeySources"`
+	// KeyAssignments are the sources assigned to keys listed by name, by key
+	KeyAssignments map[string][]string `json:"keyAssignments"`
+	// DefaultButtonSources are the sources played for buttons that are not assigned
+	DefaultButtonSources []string `json:"defaultButtonSources"`
+	// ButtonAssignments are the sources assigned to buttons, by button
+	ButtonAssignments map[string][]string `json:"buttonAssignments"`
+}
+
+var (
+	// The profile being edited in the profile builder
+	profileEditor     *profile.Editor
+	profileEditorLock sync.Mutex
+)
+
+// BeginProfileEdit starts editing an existing profile, discarding any edits that were not saved
+func (pb *ProfileBuilder) BeginProfileEdit(ref string) (EditableProfile, error) {
+	editor, err := profile.Edit(ref)
+	if err != nil {
+		return EditableProfile{}, err
+	}
+
+	profileEditorLock.Lock()
+	defer profileEditorLock.Unlock()
+
+	profileEditor = editor
+	return editableProfile(editor.Profile()), nil
+}
+
+// CancelProfileEdit discards the edits to the profile being edited
+func (pb *ProfileBuilder) CancelProfileEdit() {
+	profileEditorLock.Lock()
+	defer profileEditorLock.Unlock()
+
+	profileEditor = nil
+}
+
+// SetProfileEditDetails sets the name, description and author of the profile being edited
+func (pb *ProfileBuilder) SetProfileEditDetails(metadata ProfileMetadata) (EditableProfile, error) {
+	return editProfile(func(editor *profile.Editor) error {
+		details := editor.Profile().Details
+		details.Name = metadata.Name
+		details.Description = metadata.Description
+		details.Author = metadata.Author
+		return editor.SetDetails(details)
+	})
+}
+
+// AddProfileEditSource adds a source to the profile being edited. The audio files are copied from the given
+// paths when the profile is saved; releasePath may be empty.
+func (pb *ProfileBuilder) AddProfileEditSource(id string, pressPath string, releasePath string) (EditableProfile, error) {
+	return editProfile(func(editor *profile.Editor) error {
+		// The source is added without files, which are then set from the given paths
+		if err := editor.AddSource(id, profile.SourceFiles{}); err != nil {
+			return err
+		}
+
+		if err := editor.ReplaceAudio(id, profile.AudioSlotPress, pressPath); err != nil {
+			editor.RemoveSource(id)
+			return err
+		}
+
+		if releasePath != "" {
+			if err := editor.ReplaceAudio(id, profile.AudioSlotRelease, releasePath); err != nil {
+				editor.RemoveSource(id)
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// RemoveProfileEditSource removes a source, and the key and button assignments that use it, from the profile
+// being edited
+func (pb *ProfileBuilder) RemoveProfileEditSource(id string) (EditableProfile, error) {
+	return editProfile(func(editor *profile.Editor) error {
+		return editor.RemoveSource(id)
+	})
+}
+
+// RenameProfileEditSource renames a source of the profile being edited
+func (pb *ProfileBuilder) RenameProfileEditSource(oldID string, newID string) (EditableProfile, error) {
+	return editProfile(func(editor *profile.Editor) error {
+		return editor.RenameSource(oldID, newID)
+	})
+}
+
+// ReplaceProfileEditAudio replaces an audio file of a source of the profile being edited. The slot is "press",
+// "release", "repeat", "hold" or "release_long". An empty path removes the audio file from the source.
+func (pb *ProfileBuilder) ReplaceProfileEditAudio(sourceID string, slot string, path string) (EditableProfile, error) {
+	return editProfile(func(editor *profile.Editor) error {
+		return editor.ReplaceAudio(sourceID, profile.AudioSlot(slot), path)
+	})
+}
+
+// AssignProfileEditKeys assigns keys to sources in the profile being edited. With no sources, the keys use
+// the default sources.
+func (pb *ProfileBuilder) AssignProfileEditKeys(keys []string, sourceIDs []string) (EditableProfile, error) {
+	return editProfile(func(editor *profile.Editor) error {
+		return editor.AssignKeys(keys, sourceIDs)
+	})
+}
+
+// SetProfileEditDefaultKeySources sets the sources played for unassigned keys in the profile being edited
+func (pb *ProfileBuilder) SetProfileEditDefaultKeySources(sourceIDs []string) (EditableProfile, error) {
+	return editProfile(func(editor *profile.Editor) error {
+		return editor.SetDefaultKeySources(sourceIDs)
+	})
+}
+
+// AssignProfileEditButtons assigns buttons to sources in the profile being edited. With no sources, the
+// buttons use the default sources.
+func (pb *ProfileBuilder) AssignProfileEditButtons(buttons []string, sourceIDs []string) (EditableProfile, error) {
+	return editProfile(func(editor *profile.Editor) error {
+		return editor.AssignButtons(buttons, sourceIDs)
+	})
+}
+
+// SetProfileEditDefaultButtonSources sets the sources played for unassigned buttons in the profile being edited
+func (pb *ProfileBuilder) SetProfileEditDefaultButtonSources(sourceIDs []string) (EditableProfile, error) {
+	return editProfile(func(editor *profile.Editor) error {
+		return editor.SetDefaultButtonSources(sourceIDs)
+	})
+}
+
+// SaveProfileEdit saves the profile being edited in place. The active profiles are refreshed if they use it.
+func (pb *ProfileBuilder) SaveProfileEdit() error {
+	profileEditorLock.Lock()
+	defer profileEditorLock.Unlock()
+
+	if profileEditor == nil {
+		return fmt.Errorf("no profile is being edited")
+	}
+
+	if _, err := kbsApp.SaveProfileEdits(profileEditor); err != nil {
+		return fmt.Errorf("failed to save profile: %w", err)
+	}
+
+	profileEditor = nil
+	return nil
+}
+
+// editProfile applies an edit to the profile being edited and returns the edited profile
+func editProfile(edit func(*profile.Editor) error) (EditableProfile, error) {
+	profileEditorLock.Lock()
+	defer profileEditorLock.Unlock()
+
+	if profileEditor == nil {
+		return EditableProfile{}, fmt.Errorf("no profile is being edited")
+	}
+
+	if err := edit(profileEditor); err != nil {
+		return editableProfile(profileEditor.Profile()), err
+	}
+
+	return editableProfile(profileEditor.Profile()), nil
+}
+
+// editableProfile converts a profile being edited to its frontend representation
+func editableProfile(p *profile.Profile) EditableProfile {
+	sources := make([]EditableSource, 0, len(p.Sources))
+	for _, source := range p.Sources {
+		editable := EditableSource{ID: source.ID}
+		if config, err := source.GetSourceConfig(); err == nil {
+			editable.Press = config.Press
+			editable.Release = config.Release
+			editable.Repeat = config.Repeat
+			editable.Hold = config.Hold
+			editable.ReleaseLong = config.ReleaseLong
+		}
+		sources = append(sources, editable)
+	}
+
+	keyAssignments := make(map[string][]string)
+	for _, entry := range p.Keys.Other {
+		if entry.Keys == nil || len(entry.WhenModifiers) > 0 {
+			continue
+		}
+		for _, k := range *entry.Keys {
+			keyAssignments[k] = entry.Sound
+		}
+	}
+
+	buttonAssignments := make(map[string][]string)
+	for _, entry := range p.Buttons.Other {
+		if entry.Buttons == nil {
+			continue
+		}
+		for _, b := range *entry.Buttons {
+			buttonAssignments[b] = entry.Sound
+		}
+	}
+
+	return EditableProfile{
+		ID:                   p.ID,
+		Name:                 p.Details.Name,
+		Description:          p.Details.Description,
+		Author:               p.Details.Author,
+		Type:                 string(p.Details.DeviceType),
+		Extends:              p.Extends,
+		Sources:              sources,
+		DefaultKeySources:    append([]string{}, p.Keys.Default...),
+		KeyAssignments:       keyAssignments,
+		DefaultButtonSources: append([]string{}, p.Buttons.Default...),
+		ButtonAssignments:    buttonAssignments,
+	}
+}
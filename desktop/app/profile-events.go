@@ -0,0 +1,16 @@
+package app
+
+import (
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/app"
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// RegisterProfilesChangedEventDelegate registers a delegate that emits Wails events when profiles change on disk
+func RegisterProfilesChangedEventDelegate() {
+	app.RegisterProfilesChangedDelegate(func() {
+		// The frontend will listen for this event and refresh the library
+		if ctx != nil {
+			runtime.EventsEmit(ctx, "profiles-changed")
+		}
+	})
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"sync"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+)
+
+// ProfileLocaleState represents the language that profile names and descriptions are shown in
+type ProfileLocaleState struct {
+	// Locale is the chosen locale, or empty to follow the system language
+	Locale string `json:"locale"`
+	// Effective is the locale that is used, or empty if profiles are shown untranslated
+	Effective string `json:"effective"`
+	// Available are the locales that profiles are translated to
+	Available []string `json:"available"`
+}
+
+var (
+	// The language reported by the frontend, which is used when no locale is chosen
+	uiSystemLocale     string
+	uiSystemLocaleLock sync.RWMutex
+)
+
+// profileLocale returns the locale that profile names and descriptions are shown in. The chosen locale is used
+// if there is one, then the language reported by the frontend, then the locale of the environment.
+func profileLocale() string {
+	uiPrefsLock.RLock()
+	locale := uiPrefs.ProfileLocale
+	uiPrefsLock.RUnlock()
+	if locale != "" {
+		return locale
+	}
+
+	uiSystemLocaleLock.RLock()
+	locale = uiSystemLocale
+	uiSystemLocaleLock.RUnlock()
+	if locale != "" {
+		return locale
+	}
+
+	return profile.SystemLocale()
+}
+
+// GetProfileLocale returns the language that profile names and descriptions are shown in
+func (l *Library) GetProfileLocale() ProfileLocaleState {
+	uiPrefsLock.RLock()
+	locale := uiPrefs.ProfileLocale
+	uiPrefsLock.RUnlock()
+
+	return ProfileLocaleState{
+		Locale:    locale,
+		Effective: profile.NormalizeLocale(profileLocale()),
+		Available: profile.ListLocales(),
+	}
+}
+
+// SetProfileLocale sets the language that profile names and descriptions are shown in. An empty locale follows
+// the system language.
+func (l *Library) SetProfileLocale(locale string) error {
+	uiPrefsLock.Lock()
+	uiPrefs.ProfileLocale = profile.NormalizeLocale(locale)
+	uiPrefsLock.Unlock()
+
+	return saveUIPreferences()
+}
+
+// SetSystemLocale records the language of the system as reported by the frontend, which is used when no locale
+// is chosen
+func (l *Library) SetSystemLocale(locale string) {
+	uiSystemLocaleLock.Lock()
+	uiSystemLocale = profile.NormalizeLocale(locale)
+	uiSystemLocaleLock.Unlock()
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"strings"
+
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/app"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/rules"
+)
+
+// Rules and preferences store profile IDs, while the frontend selects profiles by name. These helpers
+// translate between the two at the binding boundary.
+
+// profileRefToName returns the name of the profile that ref refers to. References to profiles that are not
+// loaded are returned unchanged.
+func profileRefToName(ref *string) *string {
+	if ref == nil {
+		return nil
+	}
+
+	if p, ok := profile.FindProfile(*ref); ok {
+		name := p.Details.Name
+		return &name
+	}
+
+	return cloneOptionalStringPtr(ref)
+}
+
+// profileRefToID returns the ID of the profile that ref refers to. References to profiles that are not loaded
+// are returned unchanged.
+func profileRefToID(ref *string) *string {
+	if ref == nil {
+		return nil
+	}
+
+	id := profile.ResolveProfileID(*ref)
+	return &id
+}
+
+// profilesToNames returns a copy of profiles with the profile references translated to names.
+func profilesToNames(profiles rules.Profiles) rules.Profiles {
+	profiles.Keyboard = profileRefToName(profiles.Keyboard)
+	profiles.Mouse = profileRefToName(profiles.Mouse)
+	return profiles
+}
+
+// rotationToNames returns a copy of config with the profiles in the rotation pools referred to by name.
+func rotationToNames(config app.RotationConfig) app.RotationConfig {
+	config.Keyboard.Profiles = profileRefsToNames(config.Keyboard.Profiles)
+	config.Mouse.Profiles = profileRefsToNames(config.Mouse.Profiles)
+	return config
+}
+
+// rotationToIDs returns a copy of config with the profiles in the rotation pools referred to by ID.
+func rotationToIDs(config app.RotationConfig) app.RotationConfig {
+	config.Keyboard.Profiles = profileRefsToIDs(config.Keyboard.Profiles)
+	config.Mouse.Profiles = profileRefsToIDs(config.Mouse.Profiles)
+	return config
+}
+
+func profileRefsToNames(refs []string) []string {
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		names[i] = *profileRefToName(&ref)
+	}
+	return names
+}
+
+func profileRefsToIDs(refs []string) []string {
+	ids := make([]string, len(refs))
+	for i, ref := range refs {
+		ids[i] = *profileRefToID(&ref)
+	}
+	return ids
+}
+
+// profileRefMatches returns true if ref refers to the given profile, by ID or by name.
+func profileRefMatches(ref *string, p *profile.Profile) bool {
+	if ref == nil {
+		return false
+	}
+
+	return *ref == p.ID || strings.EqualFold(*ref, p.Details.Name)
+}
+
+// isDeskProfileRef returns true if ref refers to a desk profile, which is used for both the keyboard and the
+// mouse.
+func isDeskProfileRef(ref *string) bool {
+	if ref == nil {
+		return false
+	}
+
+	p, ok := profile.FindProfile(*ref)
+	return ok && p.Details.DeviceType == profile.DeviceTypeDesk
+}
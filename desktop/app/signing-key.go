@@ -0,0 +1,164 @@
+package app
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	kbs "github.com/keyboard-sounds/keyboardsounds-pro/backend"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+)
+
+var (
+	signingKey     ed25519.PrivateKey
+	signingKeyLock sync.Mutex
+)
+
+// SigningIdentity represents the identity used to sign exported profiles
+type SigningIdentity struct {
+	// Signer is the name recorded in signed exports. Exports are not signed when empty.
+	Signer string `json:"signer"`
+	// Fingerprint is the fingerprint of this installation's signing key.
+	Fingerprint string `json:"fingerprint"`
+}
+
+// loadSigningKey loads the signing key for this installation, generating it on first use.
+func loadSigningKey() (ed25519.PrivateKey, error) {
+	signingKeyLock.Lock()
+	defer signingKeyLock.Unlock()
+
+	if signingKey != nil {
+		return signingKey, nil
+	}
+
+	keyPath := filepath.Join(kbs.GetHomeDirectory(), "signing-key")
+	data, err := os.ReadFile(keyPath)
+	if err == nil {
+		seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil || len(seed) != ed25519.SeedSize {
+			return nil, fmt.Errorf("invalid signing key in %s", keyPath)
+		}
+		signingKey = ed25519.NewKeyFromSeed(seed)
+		return signingKey, nil
+	}
+	if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+
+	err = os.WriteFile(keyPath, []byte(base64.StdEncoding.EncodeToString(key.Seed())), 0600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to write signing key: %w", err)
+	}
+
+	signingKey = key
+	return signingKey, nil
+}
+
+// exportOptions returns the options used to export profiles, signing them if a signer is configured.
+func exportOptions() (profile.ExportOptions, error) {
+	uiPrefsLock.RLock()
+	signer := uiPrefs.ExportSigner
+	uiPrefsLock.RUnlock()
+
+	if signer == "" {
+		return profile.ExportOptions{}, nil
+	}
+
+	key, err := loadSigningKey()
+	if err != nil {
+		return profile.ExportOptions{}, err
+	}
+
+	return profile.ExportOptions{SigningKey: key, Signer: signer}, nil
+}
+
+// GetSigningIdentity returns the identity used to sign exported profiles
+func (l *Library) GetSigningIdentity() (SigningIdentity, error) {
+	key, err := loadSigningKey()
+	if err != nil {
+		return SigningIdentity{}, err
+	}
+
+	uiPrefsLock.RLock()
+	defer uiPrefsLock.RUnlock()
+
+	return SigningIdentity{
+		Signer:      uiPrefs.ExportSigner,
+		Fingerprint: profile.KeyFingerprint(key.Public().(ed25519.PublicKey)),
+	}, nil
+}
+
+// SetExportSigner sets the name recorded in signed exports. An empty name disables signing.
+func (l *Library) SetExportSigner(signer string) error {
+	uiPrefsLock.Lock()
+	uiPrefs.ExportSigner = strings.TrimSpace(signer)
+	uiPrefsLock.Unlock()
+
+	return saveUIPreferences()
+}
+
+// applyTrustedFingerprints trusts the signing key of this installation and the signing keys trusted in the
+// preferences when importing profile archives.
+func applyTrustedFingerprints() error {
+	key, err := loadSigningKey()
+	if err != nil {
+		return err
+	}
+
+	uiPrefsLock.RLock()
+	fingerprints := append([]string{profile.KeyFingerprint(key.Public().(ed25519.PublicKey))}, uiPrefs.TrustedFingerprints...)
+	uiPrefsLock.RUnlock()
+
+	profile.SetTrustedFingerprints(fingerprints)
+	return nil
+}
+
+// GetTrustedFingerprints returns the fingerprints of the signing keys trusted on import, besides the signing key
+// of this installation
+func (l *Library) GetTrustedFingerprints() []string {
+	uiPrefsLock.RLock()
+	defer uiPrefsLock.RUnlock()
+
+	return slices.Clone(uiPrefs.TrustedFingerprints)
+}
+
+// TrustFingerprint trusts the signing key with the given fingerprint, so that profile archives signed with it are
+// imported as trusted
+func (l *Library) TrustFingerprint(fingerprint string) error {
+	fingerprint = strings.ToLower(strings.TrimSpace(fingerprint))
+	if fingerprint == "" {
+		return fmt.Errorf("fingerprint is required")
+	}
+
+	uiPrefsLock.Lock()
+	if !slices.Contains(uiPrefs.TrustedFingerprints, fingerprint) {
+		uiPrefs.TrustedFingerprints = append(uiPrefs.TrustedFingerprints, fingerprint)
+	}
+	uiPrefsLock.Unlock()
+
+	return saveUIPreferences()
+}
+
+// UntrustFingerprint stops trusting the signing key with the given fingerprint
+func (l *Library) UntrustFingerprint(fingerprint string) error {
+	fingerprint = strings.ToLower(strings.TrimSpace(fingerprint))
+
+	uiPrefsLock.Lock()
+	uiPrefs.TrustedFingerprints = slices.DeleteFunc(uiPrefs.TrustedFingerprints, func(f string) bool {
+		return f == fingerprint
+	})
+	uiPrefsLock.Unlock()
+
+	return saveUIPreferences()
+}
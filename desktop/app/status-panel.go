@@ -3,6 +3,7 @@ package app
 import (
 	"log/slog"
 
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/app"
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/rules"
 )
@@ -44,8 +45,8 @@ func (s *StatusPanel) GetState() StatusPanelState {
 		Enabled:          kbsApp.IsEnabled(),
 		KeyboardVolume:   kbsApp.GetKeyboardVolume(),
 		MouseVolume:      kbsApp.GetMouseVolume(),
-		KeyboardProfile:  defaultProfiles.Keyboard,
-		MouseProfile:     defaultProfiles.Mouse,
+		KeyboardProfile:  profileRefToName(defaultProfiles.Keyboard),
+		MouseProfile:     profileRefToName(defaultProfiles.Mouse),
 		KeyboardProfiles: keyboardProfiles,
 		MouseProfiles:    mouseProfiles,
 	}
@@ -96,7 +97,7 @@ func (s *StatusPanel) GetMouseVolume() float64 {
 
 // GetDefaultProfiles returns the default keyboard and mouse profiles
 func (s *StatusPanel) GetDefaultProfiles() rules.Profiles {
-	profiles := rules.GetDefaultProfiles()
+	profiles := profilesToNames(rules.GetDefaultProfiles())
 	slog.Info("default profiles", "keyboard", profiles.Keyboard, "mouse", profiles.Mouse)
 	return profiles
 }
@@ -104,40 +105,79 @@ func (s *StatusPanel) GetDefaultProfiles() rules.Profiles {
 // SetDefaultProfiles sets the default keyboard and mouse profiles
 func (s *StatusPanel) SetDefaultProfiles(keyboard *string, mouse *string) error {
 	profiles := rules.Profiles{
-		Keyboard: keyboard,
-		Mouse:    mouse,
+		Keyboard: profileRefToID(keyboard),
+		Mouse:    profileRefToID(mouse),
 	}
-	return kbsApp.SetDefaultProfiles(profiles)
+	return kbsApp.SetDefaultProfiles(app.FillDeskProfiles(profiles))
 }
 
-// SetDefaultKeyboardProfile sets the default keyboard profile by name
+// SetDefaultKeyboardProfile sets the default keyboard profile by name. A desk profile is set as the default
+// mouse profile as well.
 func (s *StatusPanel) SetDefaultKeyboardProfile(name string) error {
 	current := rules.GetDefaultProfiles()
-	current.Keyboard = &name
+	current.Keyboard = profileRefToID(&name)
+	if isDeskProfileRef(current.Keyboard) {
+		current.Mouse = cloneOptionalStringPtr(current.Keyboard)
+	}
 	return kbsApp.SetDefaultProfiles(current)
 }
 
-// ClearDefaultKeyboardProfile clears the default keyboard profile (sets to nil)
+// ClearDefaultKeyboardProfile clears the default keyboard profile (sets to nil). If the keyboard and mouse
+// share a desk profile, both are cleared, since the desk profile would otherwise still apply to the keyboard.
 func (s *StatusPanel) ClearDefaultKeyboardProfile() error {
 	current := rules.GetDefaultProfiles()
+	if isDeskProfileRef(current.Mouse) {
+		current.Mouse = nil
+	}
 	current.Keyboard = nil
 	return kbsApp.SetDefaultProfiles(current)
 }
 
-// SetDefaultMouseProfile sets the default mouse profile by name
+// SetDefaultMouseProfile sets the default mouse profile by name. A desk profile is set as the default keyboard
+// profile as well.
 func (s *StatusPanel) SetDefaultMouseProfile(name string) error {
 	current := rules.GetDefaultProfiles()
-	current.Mouse = &name
+	current.Mouse = profileRefToID(&name)
+	if isDeskProfileRef(current.Mouse) {
+		current.Keyboard = cloneOptionalStringPtr(current.Mouse)
+	}
 	return kbsApp.SetDefaultProfiles(current)
 }
 
-// ClearDefaultMouseProfile clears the default mouse profile (sets to nil)
+// ClearDefaultMouseProfile clears the default mouse profile (sets to nil). If the keyboard and mouse share a
+// desk profile, both are cleared, since the desk profile would otherwise still apply to the mouse.
 func (s *StatusPanel) ClearDefaultMouseProfile() error {
 	current := rules.GetDefaultProfiles()
+	if isDeskProfileRef(current.Keyboard) {
+		current.Keyboard = nil
+	}
 	current.Mouse = nil
 	return kbsApp.SetDefaultProfiles(current)
 }
 
+// GetProfileRotation returns the rotation of the default profiles, referring to the pooled profiles by name
+func (s *StatusPanel) GetProfileRotation() app.RotationConfig {
+	return rotationToNames(kbsApp.GetProfileRotation())
+}
+
+// SetProfileRotation sets the rotation of the default profiles
+func (s *StatusPanel) SetProfileRotation(config app.RotationConfig) error {
+	if err := kbsApp.SetProfileRotation(rotationToIDs(config)); err != nil {
+		return err
+	}
+	return SaveProfileRotationToPreferences()
+}
+
+// RotateProfiles picks new keyboard and mouse profiles from the rotation pools
+func (s *StatusPanel) RotateProfiles() error {
+	return kbsApp.RotateProfiles(true, true)
+}
+
+// GetRotatedProfiles returns the names of the profiles picked by the last rotation
+func (s *StatusPanel) GetRotatedProfiles() rules.Profiles {
+	return profilesToNames(kbsApp.GetRotatedProfiles())
+}
+
 // GetKeyboardProfiles returns a list of available keyboard profile names
 func (s *StatusPanel) GetKeyboardProfiles() []string {
 	profiles := profile.GetKeyboardProfiles()
@@ -2,6 +2,7 @@ package app
 
 import (
 	"encoding/json"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
@@ -12,6 +13,8 @@ import (
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/audio"
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/app"
 	"github.com/keyboard-sounds/keyboardsounds-pro/backend/oskhelpers"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/profile"
+	"github.com/keyboard-sounds/keyboardsounds-pro/backend/rules"
 )
 
 // Persisted values for UIPreferences.EnabledSoundOnStart (start-button confirmation sound).
@@ -53,6 +56,18 @@ type VolumePreferences struct {
 	MouseVolume    float64 `json:"mouseVolume"`
 }
 
+// KeyRepeatPreferences stores persisted key repeat settings
+type KeyRepeatPreferences struct {
+	Mode    string  `json:"mode"`
+	MaxRate float64 `json:"maxRate"` // sounds per second when throttled
+}
+
+// ProfileBlendPreferences stores the persisted profiles mixed with the active keyboard and mouse profiles
+type ProfileBlendPreferences struct {
+	Keyboard app.ProfileBlend `json:"keyboard"`
+	Mouse    app.ProfileBlend `json:"mouse"`
+}
+
 // OSKHelperPreferences stores persisted OSK Helper settings
 type OSKHelperPreferences struct {
 	Enabled           bool   `json:"enabled"`
@@ -85,8 +100,17 @@ type UIPreferences struct {
 	InAppMouseProfile    *string `json:"inAppMouseProfile,omitempty"`
 	AudioEffects                AudioEffectsPreferences `json:"audioEffects"`
 	Volume                      VolumePreferences       `json:"volume"`
+	KeyRepeat                   KeyRepeatPreferences    `json:"keyRepeat"`
+	ProfileBlend                ProfileBlendPreferences `json:"profileBlend"`
+	ProfileRotation             app.RotationConfig      `json:"profileRotation"`
 	OSKHelper                   OSKHelperPreferences    `json:"oskHelper"`
 	UpdateNotifiedAndIgnored    string                  `json:"updateNotifiedAndIgnored"`
+	ExportSigner                string                  `json:"exportSigner"`
+	// The fingerprints of the signing keys whose signed profile archives are trusted on import, besides the
+	// signing key of this installation.
+	TrustedFingerprints         []string                `json:"trustedFingerprints"`
+	// The locale that profile names and descriptions are shown in. Empty follows the system language.
+	ProfileLocale               string                  `json:"profileLocale"`
 	Analytics                   Analytics               `json:"analytics"`
 }
 
@@ -127,6 +151,18 @@ func loadUIPreferences() {
 			KeyboardVolume: 1.0,
 			MouseVolume:    1.0,
 		},
+		KeyRepeat: KeyRepeatPreferences{
+			Mode:    string(app.KeyRepeatModeOff),
+			MaxRate: app.DefaultKeyRepeatMaxRate,
+		},
+		ProfileBlend: ProfileBlendPreferences{
+			Keyboard: app.DefaultProfileBlend(),
+			Mouse:    app.DefaultProfileBlend(),
+		},
+		ProfileRotation: app.RotationConfig{
+			Mode:            app.RotationModeOff,
+			IntervalMinutes: app.DefaultRotationIntervalMinutes,
+		},
 		OSKHelper: OSKHelperPreferences{
 			Enabled:           false,
 			FontSize:          72,
@@ -249,6 +285,110 @@ func SaveVolumeToPreferences() error {
 	return saveUIPreferences()
 }
 
+// ApplyKeyRepeatFromPreferences applies the saved key repeat settings to the application
+// This should be called after the application is initialized
+func ApplyKeyRepeatFromPreferences() {
+	uiPrefsLock.RLock()
+	defer uiPrefsLock.RUnlock()
+
+	if uiPrefs == nil {
+		return
+	}
+
+	kbsApp.SetKeyRepeat(app.KeyRepeatMode(uiPrefs.KeyRepeat.Mode), uiPrefs.KeyRepeat.MaxRate)
+}
+
+// SaveKeyRepeatToPreferences saves the current key repeat settings to preferences
+func SaveKeyRepeatToPreferences() error {
+	mode, maxRate := kbsApp.GetKeyRepeat()
+
+	uiPrefsLock.Lock()
+	uiPrefs.KeyRepeat = KeyRepeatPreferences{
+		Mode:    string(mode),
+		MaxRate: maxRate,
+	}
+	uiPrefsLock.Unlock()
+
+	return saveUIPreferences()
+}
+
+// ApplyProfileBlendFromPreferences applies the saved profile blends to the application. Blend layers whose
+// profiles are no longer available are skipped.
+// This should be called after the application is initialized
+func ApplyProfileBlendFromPreferences() {
+	uiPrefsLock.RLock()
+	if uiPrefs == nil {
+		uiPrefsLock.RUnlock()
+		return
+	}
+	keyboardBlend := availableBlendLayers(uiPrefs.ProfileBlend.Keyboard)
+	mouseBlend := availableBlendLayers(uiPrefs.ProfileBlend.Mouse)
+	uiPrefsLock.RUnlock()
+
+	if err := kbsApp.SetKeyboardBlend(keyboardBlend); err != nil {
+		slog.Warn("failed to apply keyboard profile blend", "error", err)
+	}
+	if err := kbsApp.SetMouseBlend(mouseBlend); err != nil {
+		slog.Warn("failed to apply mouse profile blend", "error", err)
+	}
+}
+
+// availableBlendLayers returns a copy of blend without the layers whose profiles are not loaded
+func availableBlendLayers(blend app.ProfileBlend) app.ProfileBlend {
+	layers := make([]app.BlendLayer, 0, len(blend.Layers))
+	for _, layer := range blend.Layers {
+		if _, ok := profile.FindProfile(layer.Profile); !ok {
+			slog.Warn("skipping blended profile that is not available", "profile", layer.Profile)
+			continue
+		}
+		layers = append(layers, layer)
+	}
+	blend.Layers = layers
+	return blend
+}
+
+// SaveProfileBlendToPreferences saves the current profile blends to preferences
+func SaveProfileBlendToPreferences() error {
+	uiPrefsLock.Lock()
+	uiPrefs.ProfileBlend = ProfileBlendPreferences{
+		Keyboard: kbsApp.GetKeyboardBlend(),
+		Mouse:    kbsApp.GetMouseBlend(),
+	}
+	uiPrefsLock.Unlock()
+
+	return saveUIPreferences()
+}
+
+// ApplyProfileRotationFromPreferences applies the saved profile rotation to the application. In the session
+// and timer modes, this picks the profiles for the session.
+// This should be called after the application is initialized
+func ApplyProfileRotationFromPreferences() {
+	uiPrefsLock.RLock()
+	if uiPrefs == nil {
+		uiPrefsLock.RUnlock()
+		return
+	}
+	config := uiPrefs.ProfileRotation
+	uiPrefsLock.RUnlock()
+
+	if config.Mode == "" {
+		config.Mode = app.RotationModeOff
+	}
+
+	if err := kbsApp.SetProfileRotation(config); err != nil {
+		slog.Warn("failed to apply profile rotation", "error", err)
+	}
+}
+
+// SaveProfileRotationToPreferences saves the current profile rotation to preferences
+func SaveProfileRotationToPreferences() error {
+	uiPrefsLock.Lock()
+	uiPrefs.ProfileRotation = kbsApp.GetProfileRotation()
+	uiPrefsLock.Unlock()
+
+	return saveUIPreferences()
+}
+
 // SaveAudioEffectsToPreferences saves the current audio effects state to preferences
 func SaveAudioEffectsToPreferences() error {
 	// Get current state from application
@@ -389,7 +529,7 @@ func cloneOptionalStringPtr(p *string) *string {
 	return &s
 }
 
-// GetInAppKeyboardProfile returns the keyboard profile name used when this app is focused, or nil for "Use Default".
+// GetInAppKeyboardProfile returns the keyboard profile ID used when this app is focused, or nil for "Use Default".
 func GetInAppKeyboardProfile() *string {
 	uiPrefsLock.RLock()
 	defer uiPrefsLock.RUnlock()
@@ -399,7 +539,7 @@ func GetInAppKeyboardProfile() *string {
 	return cloneOptionalStringPtr(uiPrefs.InAppKeyboardProfile)
 }
 
-// GetInAppMouseProfile returns the mouse profile name used when this app is focused, or nil for "Use Default".
+// GetInAppMouseProfile returns the mouse profile ID used when this app is focused, or nil for "Use Default".
 func GetInAppMouseProfile() *string {
 	uiPrefsLock.RLock()
 	defer uiPrefsLock.RUnlock()
@@ -412,7 +552,7 @@ func GetInAppMouseProfile() *string {
 // SetInAppKeyboardProfile sets the in-focus keyboard profile override (nil = follow application rules / defaults).
 func SetInAppKeyboardProfile(name *string) error {
 	uiPrefsLock.Lock()
-	uiPrefs.InAppKeyboardProfile = cloneOptionalStringPtr(name)
+	uiPrefs.InAppKeyboardProfile = profileRefToID(name)
 	kb := uiPrefs.InAppKeyboardProfile
 	ms := uiPrefs.InAppMouseProfile
 	uiPrefsLock.Unlock()
@@ -426,7 +566,7 @@ func SetInAppKeyboardProfile(name *string) error {
 // SetInAppMouseProfile sets the in-focus mouse profile override (nil = follow application rules / defaults).
 func SetInAppMouseProfile(name *string) error {
 	uiPrefsLock.Lock()
-	uiPrefs.InAppMouseProfile = cloneOptionalStringPtr(name)
+	uiPrefs.InAppMouseProfile = profileRefToID(name)
 	kb := uiPrefs.InAppKeyboardProfile
 	ms := uiPrefs.InAppMouseProfile
 	uiPrefsLock.Unlock()
@@ -438,7 +578,13 @@ func SetInAppMouseProfile(name *string) error {
 }
 
 // ApplyInAppFocusProfilesFromPreferences applies saved in-app focus profile overrides to the backend.
+// Overrides saved before profiles had stable IDs refer to profiles by name and are rewritten to IDs first.
 func ApplyInAppFocusProfilesFromPreferences() {
+	err := rewriteInAppFocusProfileReferences(profile.ResolveProfileID)
+	if err != nil {
+		slog.Warn("failed to migrate in-app focus profile references", "error", err)
+	}
+
 	uiPrefsLock.RLock()
 	defer uiPrefsLock.RUnlock()
 	if uiPrefs == nil {
@@ -447,6 +593,27 @@ func ApplyInAppFocusProfilesFromPreferences() {
 	kbsApp.SetInAppFocusProfiles(uiPrefs.InAppKeyboardProfile, uiPrefs.InAppMouseProfile)
 }
 
+// rewriteInAppFocusProfileReferences replaces the saved in-app focus profile references with the value
+// returned by rewrite, saving the preferences if any of them changed.
+func rewriteInAppFocusProfileReferences(rewrite func(ref string) string) error {
+	uiPrefsLock.Lock()
+	if uiPrefs == nil {
+		uiPrefsLock.Unlock()
+		return nil
+	}
+	profiles := rules.Profiles{Keyboard: uiPrefs.InAppKeyboardProfile, Mouse: uiPrefs.InAppMouseProfile}
+	changed := profiles.RewriteReferences(rewrite)
+	uiPrefs.InAppKeyboardProfile = profiles.Keyboard
+	uiPrefs.InAppMouseProfile = profiles.Mouse
+	uiPrefsLock.Unlock()
+
+	if !changed {
+		return nil
+	}
+
+	return saveUIPreferences()
+}
+
 // GetNotifyOnUpdate returns whether notifications should be shown when an update is available
 func GetNotifyOnUpdate() bool {
 	uiPrefsLock.RLock()
@@ -97,6 +97,7 @@ import {
   OpenProfileFolder,
   ImportProfile,
   ExportProfile,
+  SetSystemLocale,
 } from "../wailsjs/go/app/Library";
 import { EventsOn, Environment } from "../wailsjs/runtime/runtime";
 import { getMenuItemsForPlatform } from "./constants";
@@ -127,6 +128,13 @@ function App() {
   const [macInputMonitoringBlocked, setMacInputMonitoringBlocked] =
     useState(false);
 
+  // Report the system language, which profile names and descriptions are translated to by default.
+  useEffect(() => {
+    SetSystemLocale(navigator.language || "").catch((err) => {
+      console.error("Failed to set system locale:", err);
+    });
+  }, []);
+
   // Platform + macOS Input Monitoring gate (must complete before main UI / Init-backed state loads)
   useEffect(() => {
     let cancelled = false;
@@ -221,6 +229,7 @@ function App() {
   const [librarySearchQuery, setLibrarySearchQuery] = useState("");
   const [libraryKeyboardProfiles, setLibraryKeyboardProfiles] = useState([]);
   const [libraryMouseProfiles, setLibraryMouseProfiles] = useState([]);
+  const [libraryDeskProfiles, setLibraryDeskProfiles] = useState([]);
   const [libraryLoading, setLibraryLoading] = useState(true);
 
   // Load library state from backend
@@ -230,12 +239,15 @@ function App() {
       const state = await GetLibraryState();
       const kb = state.keyboardProfiles || [];
       const ms = state.mouseProfiles || [];
+      const desk = state.deskProfiles || [];
       setLibraryKeyboardProfiles(kb);
       setLibraryMouseProfiles(ms);
+      setLibraryDeskProfiles(desk);
       // Single source of truth for profile picker options (matches Library page). Avoids relying on
       // GetState().keyboardProfiles, which hotkey refresh can overwrite with empty slices.
-      setKeyboardProfiles(kb.map((p) => p.name || p.id));
-      setMouseProfiles(ms.map((p) => p.name || p.id));
+      // Desk profiles can be picked for either device.
+      setKeyboardProfiles([...kb, ...desk].map((p) => p.name || p.id));
+      setMouseProfiles([...ms, ...desk].map((p) => p.name || p.id));
     } catch (error) {
       console.error("Failed to load library state:", error);
     } finally {
@@ -352,6 +364,20 @@ function App() {
     };
   }, [loadState]);
 
+  // Listen for profiles changing on disk and refresh the library
+  useEffect(() => {
+    const unsubscribe = EventsOn("profiles-changed", () => {
+      loadLibraryState();
+      loadState();
+    });
+
+    return () => {
+      if (unsubscribe) {
+        unsubscribe();
+      }
+    };
+  }, [loadLibraryState, loadState]);
+
   /** Play UI sound when the status panel reports enabled (not on initial hydrate). */
   const pauseSoundPrevRef = useRef(null);
   useEffect(() => {
@@ -484,13 +510,15 @@ function App() {
         } else {
           await ClearDefaultKeyboardProfile();
         }
+        // A desk profile is selected for both devices, so refresh both profiles
+        await loadState();
         // Refresh library state to update inUse status
         await loadLibraryState();
       } catch (error) {
         console.error("Failed to set keyboard profile:", error);
       }
     },
-    [loadLibraryState],
+    [loadState, loadLibraryState],
   );
 
   // Handle mouse profile change
@@ -504,13 +532,15 @@ function App() {
         } else {
           await ClearDefaultMouseProfile();
         }
+        // A desk profile is selected for both devices, so refresh both profiles
+        await loadState();
         // Refresh library state to update inUse status
         await loadLibraryState();
       } catch (error) {
         console.error("Failed to set mouse profile:", error);
       }
     },
-    [loadLibraryState],
+    [loadState, loadLibraryState],
   );
 
   // Audio effects state - Keyboard
@@ -993,13 +1023,15 @@ function App() {
           }
           setMouseProfile(newValue);
         }
+        // A desk profile is selected for both devices, so refresh both profiles
+        await loadState();
         // Refresh library state to update inUse status
         await loadLibraryState();
       } catch (error) {
         console.error("Failed to update default profile:", error);
       }
     },
-    [loadLibraryState],
+    [loadState, loadLibraryState],
   );
 
   const handleBrowseForExecutable = useCallback(async () => {
@@ -1022,8 +1054,9 @@ function App() {
 
   // Handler for removing library profiles
   const handleImportProfile = useCallback(async () => {
-    await ImportProfile();
+    const result = await ImportProfile();
     await loadLibraryState();
+    return result;
   }, [loadLibraryState]);
 
   const handleExportProfile = useCallback(async (profileId) => {
@@ -1041,6 +1074,9 @@ function App() {
       setLibraryMouseProfiles((prev) =>
         prev.filter((p) => p.id !== profileName),
       );
+      setLibraryDeskProfiles((prev) =>
+        prev.filter((p) => p.id !== profileName),
+      );
       // Also update status panel profile lists
       setKeyboardProfiles((prev) => prev.filter((p) => p !== profileName));
       setMouseProfiles((prev) => prev.filter((p) => p !== profileName));
@@ -1082,6 +1118,8 @@ function App() {
             setPanKeyPositionKeys={setPanKeyPositionKeys}
             panEnabledMouse={panEnabledMouse}
             setPanEnabledMouse={setPanEnabledMouse}
+            keyboardProfiles={keyboardProfiles}
+            mouseProfiles={mouseProfiles}
           />
         );
       case "Application Rules":
@@ -1129,6 +1167,7 @@ function App() {
           <LibraryPage
             keyboardProfiles={libraryKeyboardProfiles}
             mouseProfiles={libraryMouseProfiles}
+            deskProfiles={libraryDeskProfiles}
             defaultKeyboardProfile={
               keyboardProfile === "None" ? null : keyboardProfile
             }
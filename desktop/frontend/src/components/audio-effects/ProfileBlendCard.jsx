@@ -0,0 +1,205 @@
+import { useState, useEffect } from 'react';
+import { Box, Typography, Slider, Select, MenuItem, FormControl, IconButton, Button, Tooltip } from '@mui/material';
+import AddIcon from '@mui/icons-material/Add';
+import DeleteOutlineIcon from '@mui/icons-material/DeleteOutline';
+import { GlassCard } from '../common';
+import { selectMenuProps } from '../../constants';
+import {
+  GetKeyboardBlend,
+  SetKeyboardBlend,
+  GetMouseBlend,
+  SetMouseBlend,
+} from '../../../wailsjs/go/app/AudioEffects';
+
+const defaultBlend = { weight: 1, layers: [] };
+
+const sliderSx = {
+  color: 'var(--accent-primary)',
+  '& .MuiSlider-thumb': {
+    backgroundColor: 'var(--accent-primary)',
+    border: '2px solid var(--bg-primary)',
+    width: '16px',
+    height: '16px',
+    '&:hover': {
+      boxShadow: '0 0 0 8px var(--accent-bg)',
+    },
+  },
+  '& .MuiSlider-track': {
+    backgroundColor: 'var(--accent-primary)',
+    border: 'none',
+    height: '4px',
+  },
+  '& .MuiSlider-rail': {
+    backgroundColor: 'var(--hover-bg-light)',
+    height: '4px',
+  },
+};
+
+const selectSx = {
+  backgroundColor: 'var(--input-bg)',
+  color: 'var(--text-primary)',
+  borderRadius: '8px',
+  fontSize: '13px',
+  '& .MuiOutlinedInput-notchedOutline': {
+    borderColor: 'var(--input-border)',
+  },
+  '&:hover .MuiOutlinedInput-notchedOutline': {
+    borderColor: 'var(--accent-primary)',
+  },
+  '& .MuiSelect-select': {
+    padding: '6px 12px',
+  },
+  '& .MuiSvgIcon-root': {
+    color: 'var(--text-secondary)',
+  },
+};
+
+function WeightSlider({ label, value, onChange, onCommit }) {
+  return (
+    <Box sx={{ display: 'flex', alignItems: 'center', gap: '16px', flex: 1, minWidth: 0 }}>
+      {label && (
+        <Typography sx={{ color: 'var(--text-primary)', fontSize: '14px', fontWeight: 500, minWidth: '120px' }}>
+          {label}
+        </Typography>
+      )}
+      <Slider
+        value={Math.round(value * 100)}
+        onChange={(e, newValue) => onChange(newValue / 100)}
+        onChangeCommitted={(e, newValue) => onCommit(newValue / 100)}
+        min={0}
+        max={100}
+        step={5}
+        size="small"
+        valueLabelDisplay="auto"
+        valueLabelFormat={(v) => `${v}%`}
+        sx={sliderSx}
+      />
+      <Typography sx={{ color: 'var(--text-tertiary)', fontSize: '12px', minWidth: '36px', textAlign: 'right' }}>
+        {Math.round(value * 100)}%
+      </Typography>
+    </Box>
+  );
+}
+
+function ProfileBlendCard({ isKeyboard, profiles }) {
+  const [blend, setBlend] = useState(defaultBlend);
+
+  const getBlend = isKeyboard ? GetKeyboardBlend : GetMouseBlend;
+  const setBackendBlend = isKeyboard ? SetKeyboardBlend : SetMouseBlend;
+
+  useEffect(() => {
+    getBlend()
+      .then((state) => setBlend({ weight: state.weight, layers: state.layers || [] }))
+      .catch((err) => {
+        console.error('Failed to load profile blend:', err);
+      });
+  }, [isKeyboard]);
+
+  const saveBlend = (newBlend) => {
+    setBlend(newBlend);
+    setBackendBlend(newBlend)
+      .catch((err) => {
+        console.error('Failed to set profile blend:', err);
+        // Reload the blend to revert the change
+        return getBlend().then((state) => setBlend({ weight: state.weight, layers: state.layers || [] }));
+      });
+  };
+
+  const updateLayer = (index, changes) =>
+    blend.layers.map((layer, i) => (i === index ? { ...layer, ...changes } : layer));
+
+  const availableProfiles = profiles || [];
+
+  return (
+    <GlassCard sx={{ marginBottom: '24px' }}>
+      {/* Header */}
+      <Typography
+        variant="h6"
+        sx={{
+          color: 'var(--text-primary)',
+          fontSize: '18px',
+          fontWeight: 600,
+          marginBottom: '8px',
+        }}
+      >
+        Profile Blend
+      </Typography>
+
+      {/* Description */}
+      <Typography
+        sx={{
+          color: 'var(--text-secondary)',
+          fontSize: '13px',
+          lineHeight: 1.5,
+          marginBottom: '20px',
+        }}
+      >
+        Layers other profiles on top of the active {isKeyboard ? 'keyboard' : 'mouse'} profile. Each profile picks its own sound for every {isKeyboard ? 'key press' : 'click'}, and the sounds play together at the volume set for each profile.
+      </Typography>
+
+      <Box sx={{ display: 'flex', flexDirection: 'column', gap: '12px' }}>
+        <WeightSlider
+          label="Active Profile"
+          value={blend.weight}
+          onChange={(weight) => setBlend({ ...blend, weight })}
+          onCommit={(weight) => saveBlend({ ...blend, weight })}
+        />
+
+        {blend.layers.map((layer, index) => (
+          <Box key={index} sx={{ display: 'flex', alignItems: 'center', gap: '16px' }}>
+            <FormControl size="small" sx={{ minWidth: '120px', maxWidth: '200px' }}>
+              <Select
+                value={availableProfiles.includes(layer.profile) ? layer.profile : ''}
+                onChange={(e) => saveBlend({ ...blend, layers: updateLayer(index, { profile: e.target.value }) })}
+                displayEmpty
+                renderValue={(v) => (v === '' ? layer.profile || 'Select profile' : v)}
+                MenuProps={selectMenuProps}
+                sx={selectSx}
+              >
+                {availableProfiles.map((name) => (
+                  <MenuItem key={name} value={name}>
+                    {name}
+                  </MenuItem>
+                ))}
+              </Select>
+            </FormControl>
+            <WeightSlider
+              value={layer.weight}
+              onChange={(weight) => setBlend({ ...blend, layers: updateLayer(index, { weight }) })}
+              onCommit={(weight) => saveBlend({ ...blend, layers: updateLayer(index, { weight }) })}
+            />
+            <Tooltip title="Remove from blend">
+              <IconButton
+                size="small"
+                onClick={() => saveBlend({ ...blend, layers: blend.layers.filter((_, i) => i !== index) })}
+                sx={{ color: 'var(--text-tertiary)', '&:hover': { color: '#f87171' } }}
+              >
+                <DeleteOutlineIcon fontSize="small" />
+              </IconButton>
+            </Tooltip>
+          </Box>
+        ))}
+
+        <Box>
+          <Button
+            size="small"
+            startIcon={<AddIcon />}
+            disabled={availableProfiles.length === 0}
+            onClick={() =>
+              saveBlend({ ...blend, layers: [...blend.layers, { profile: availableProfiles[0], weight: 0.3 }] })
+            }
+            sx={{
+              color: 'var(--accent-primary)',
+              textTransform: 'none',
+              fontSize: '13px',
+            }}
+          >
+            Add Profile
+          </Button>
+        </Box>
+      </Box>
+    </GlassCard>
+  );
+}
+
+export default ProfileBlendCard;
@@ -1,4 +1,4 @@
 export { default as PitchShiftCard } from './PitchShiftCard';
 export { default as EqualizerCard } from './EqualizerCard';
 export { default as PanCard } from './PanCard';
-
+export { default as ProfileBlendCard } from './ProfileBlendCard';
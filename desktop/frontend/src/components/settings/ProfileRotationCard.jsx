@@ -0,0 +1,265 @@
+import { useState, useEffect } from 'react';
+import { Box, Typography, Select, MenuItem, FormControl, TextField, Button, Chip } from '@mui/material';
+import ShuffleIcon from '@mui/icons-material/Shuffle';
+import { GlassCard } from '../common';
+import { selectMenuProps, rotationModeOptions } from '../../constants';
+import {
+  GetProfileRotation,
+  SetProfileRotation,
+  RotateProfiles,
+  GetRotatedProfiles,
+} from '../../../wailsjs/go/app/StatusPanel';
+
+const emptyPool = { profiles: [], tags: [] };
+const defaultRotation = { mode: 'off', intervalMinutes: 60, keyboard: emptyPool, mouse: emptyPool };
+
+const selectSx = {
+  backgroundColor: 'var(--input-bg)',
+  color: 'var(--text-primary)',
+  borderRadius: '8px',
+  fontSize: '13px',
+  '& .MuiOutlinedInput-notchedOutline': {
+    borderColor: 'var(--input-border)',
+  },
+  '&:hover .MuiOutlinedInput-notchedOutline': {
+    borderColor: 'var(--accent-primary)',
+  },
+  '& .MuiSelect-select': {
+    padding: '6px 12px',
+  },
+  '& .MuiSvgIcon-root': {
+    color: 'var(--text-secondary)',
+  },
+};
+
+const textFieldSx = {
+  '& .MuiOutlinedInput-root': {
+    backgroundColor: 'var(--input-bg)',
+    borderRadius: '8px',
+    fontSize: '13px',
+    color: 'var(--text-primary)',
+    '& fieldset': {
+      borderColor: 'var(--input-border)',
+    },
+    '&:hover fieldset': {
+      borderColor: 'var(--accent-primary)',
+    },
+    '&.Mui-focused fieldset': {
+      borderColor: 'var(--accent-primary)',
+    },
+  },
+};
+
+const normalizePool = (pool) => ({
+  profiles: pool?.profiles || [],
+  tags: pool?.tags || [],
+});
+
+const normalizeRotation = (state) => ({
+  ...state,
+  keyboard: normalizePool(state.keyboard),
+  mouse: normalizePool(state.mouse),
+});
+
+const parseTags = (text) =>
+  text
+    .split(',')
+    .map((tag) => tag.trim())
+    .filter((tag) => tag !== '');
+
+function RotationPoolRow({ label, pool, profiles, current, onChange }) {
+  const [tagsText, setTagsText] = useState(pool.tags.join(', '));
+
+  useEffect(() => {
+    setTagsText(pool.tags.join(', '));
+  }, [pool.tags.join(',')]);
+
+  return (
+    <Box sx={{ display: 'flex', flexDirection: 'column', gap: '8px' }}>
+      <Box sx={{ display: 'flex', alignItems: 'center', justifyContent: 'space-between' }}>
+        <Typography sx={{ color: 'var(--text-primary)', fontSize: '14px', fontWeight: 500 }}>
+          {label}
+        </Typography>
+        {current && (
+          <Typography sx={{ color: 'var(--text-tertiary)', fontSize: '12px' }}>
+            Current: {current}
+          </Typography>
+        )}
+      </Box>
+      <Box sx={{ display: 'flex', alignItems: 'center', gap: '12px' }}>
+        <FormControl size="small" sx={{ flex: 1, minWidth: 0 }}>
+          <Select
+            multiple
+            value={pool.profiles}
+            onChange={(e) => onChange({ ...pool, profiles: e.target.value })}
+            displayEmpty
+            renderValue={(selected) =>
+              selected.length === 0 ? (
+                <Typography sx={{ color: 'var(--text-tertiary)', fontSize: '13px' }}>
+                  No profiles
+                </Typography>
+              ) : (
+                <Box sx={{ display: 'flex', flexWrap: 'wrap', gap: '4px' }}>
+                  {selected.map((name) => (
+                    <Chip key={name} label={name} size="small" />
+                  ))}
+                </Box>
+              )
+            }
+            MenuProps={selectMenuProps}
+            sx={selectSx}
+          >
+            {profiles.map((name) => (
+              <MenuItem key={name} value={name}>
+                {name}
+              </MenuItem>
+            ))}
+          </Select>
+        </FormControl>
+        <TextField
+          size="small"
+          placeholder="Tags, comma separated"
+          value={tagsText}
+          onChange={(e) => setTagsText(e.target.value)}
+          onBlur={() => onChange({ ...pool, tags: parseTags(tagsText) })}
+          sx={{ ...textFieldSx, width: '200px' }}
+        />
+      </Box>
+    </Box>
+  );
+}
+
+function ProfileRotationCard({ keyboardProfiles, mouseProfiles }) {
+  const [rotation, setRotation] = useState(defaultRotation);
+  const [intervalText, setIntervalText] = useState('60');
+  const [rotated, setRotated] = useState({ keyboard: null, mouse: null });
+
+  const loadRotation = () =>
+    Promise.all([GetProfileRotation(), GetRotatedProfiles()])
+      .then(([state, current]) => {
+        setRotation(normalizeRotation(state));
+        setIntervalText(String(state.intervalMinutes));
+        setRotated(current);
+      })
+      .catch((err) => {
+        console.error('Failed to load profile rotation:', err);
+      });
+
+  useEffect(() => {
+    loadRotation();
+  }, []);
+
+  const saveRotation = (newRotation) => {
+    setRotation(newRotation);
+    SetProfileRotation(newRotation)
+      .catch((err) => {
+        console.error('Failed to set profile rotation:', err);
+      })
+      .finally(loadRotation);
+  };
+
+  const rotateNow = () => {
+    RotateProfiles()
+      .catch((err) => {
+        console.error('Failed to rotate profiles:', err);
+      })
+      .finally(loadRotation);
+  };
+
+  return (
+    <GlassCard sx={{ marginBottom: '24px' }}>
+      <Typography
+        variant="h6"
+        sx={{
+          color: 'var(--text-primary)',
+          fontSize: '18px',
+          fontWeight: 600,
+          marginBottom: '8px',
+        }}
+      >
+        Profile Rotation
+      </Typography>
+
+      <Typography
+        sx={{
+          color: 'var(--text-secondary)',
+          fontSize: '13px',
+          lineHeight: 1.5,
+          marginBottom: '20px',
+        }}
+      >
+        Picks a random profile from a pool in place of the default profile. Pools can list profiles or match
+        profiles by tag. Applications with their own rule keep their profiles. Use the Rotate Profiles hotkey to
+        pick new profiles at any time.
+      </Typography>
+
+      <Box sx={{ display: 'flex', flexDirection: 'column', gap: '16px' }}>
+        <Box sx={{ display: 'flex', alignItems: 'center', gap: '12px' }}>
+          <FormControl size="small" sx={{ minWidth: 160 }}>
+            <Select
+              value={rotation.mode}
+              onChange={(e) => saveRotation({ ...rotation, mode: e.target.value })}
+              MenuProps={selectMenuProps}
+              sx={selectSx}
+            >
+              {rotationModeOptions.map(({ value, label }) => (
+                <MenuItem key={value} value={value}>
+                  {label}
+                </MenuItem>
+              ))}
+            </Select>
+          </FormControl>
+          {rotation.mode === 'timer' && (
+            <TextField
+              size="small"
+              type="number"
+              value={intervalText}
+              onChange={(e) => setIntervalText(e.target.value)}
+              onBlur={() => saveRotation({ ...rotation, intervalMinutes: Number(intervalText) })}
+              inputProps={{ min: 1, 'aria-label': 'Minutes between rotations' }}
+              InputProps={{
+                endAdornment: (
+                  <Typography sx={{ color: 'var(--text-tertiary)', fontSize: '12px', marginLeft: '4px' }}>
+                    min
+                  </Typography>
+                ),
+              }}
+              sx={{ ...textFieldSx, width: '110px' }}
+            />
+          )}
+          <Box sx={{ flexGrow: 1 }} />
+          <Button
+            size="small"
+            startIcon={<ShuffleIcon />}
+            disabled={rotation.mode === 'off'}
+            onClick={rotateNow}
+            sx={{
+              color: 'var(--accent-primary)',
+              textTransform: 'none',
+              fontSize: '13px',
+            }}
+          >
+            Rotate Now
+          </Button>
+        </Box>
+
+        <RotationPoolRow
+          label="Keyboard Pool"
+          pool={rotation.keyboard}
+          profiles={keyboardProfiles || []}
+          current={rotated.keyboard}
+          onChange={(keyboard) => saveRotation({ ...rotation, keyboard })}
+        />
+        <RotationPoolRow
+          label="Mouse Pool"
+          pool={rotation.mouse}
+          profiles={mouseProfiles || []}
+          current={rotated.mouse}
+          onChange={(mouse) => saveRotation({ ...rotation, mouse })}
+        />
+      </Box>
+    </GlassCard>
+  );
+}
+
+export default ProfileRotationCard;
@@ -0,0 +1 @@
+export { default as ProfileRotationCard } from './ProfileRotationCard';
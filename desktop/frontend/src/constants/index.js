@@ -24,6 +24,21 @@ export const enabledSoundOptions = [
 
 export const DEFAULT_ENABLED_SOUND = ENABLED_SOUND_SOFT;
 
+/** Key repeat modes (matches backend/app/key-repeat.go). */
+export const keyRepeatOptions = [
+  { value: 'off', label: 'Off' },
+  { value: 'all', label: 'Every Repeat' },
+  { value: 'throttled', label: 'Throttled' },
+];
+
+/** Profile rotation modes (matches backend/app/profile-rotation.go). */
+export const rotationModeOptions = [
+  { value: 'off', label: 'Off' },
+  { value: 'manual', label: 'Hotkey Only' },
+  { value: 'session', label: 'Every Launch' },
+  { value: 'timer', label: 'On a Timer' },
+];
+
 export const menuItems = [
   { name: 'Application Rules', icon: GavelIcon },
   { name: 'Audio Effects', icon: GraphicEqIcon },
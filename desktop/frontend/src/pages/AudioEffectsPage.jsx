@@ -2,7 +2,7 @@ import { Box, FormControl, Select, MenuItem } from '@mui/material';
 import KeyboardIcon from '@mui/icons-material/Keyboard';
 import MouseIcon from '@mui/icons-material/Mouse';
 import { PageHeader } from '../components/common';
-import { PitchShiftCard, EqualizerCard, PanCard } from '../components/audio-effects';
+import { PitchShiftCard, EqualizerCard, PanCard, ProfileBlendCard } from '../components/audio-effects';
 import { selectMenuProps } from '../constants';
 
 function AudioEffectsPage({
@@ -36,6 +36,9 @@ function AudioEffectsPage({
   // Pan settings - Mouse (mouse only has enabled/disabled, always random mode)
   panEnabledMouse,
   setPanEnabledMouse,
+  // Profiles that can be blended
+  keyboardProfiles,
+  mouseProfiles,
 }) {
   const isKeyboard = audioInputMethod === 'keyboard';
 
@@ -115,6 +118,12 @@ function AudioEffectsPage({
         setKeyPositionKeys={setPanKeyPositionKeys}
         isKeyboard={isKeyboard}
       />
+
+      {/* Profile Blend Section */}
+      <ProfileBlendCard
+        isKeyboard={isKeyboard}
+        profiles={isKeyboard ? keyboardProfiles : mouseProfiles}
+      />
     </Box>
   );
 }
@@ -50,6 +50,7 @@ const ACTION_OPTIONS = [
     { value: "increase-volume", label: "Increase Volume" },
     { value: "decrease-volume", label: "Decrease Volume" },
     { value: "toggle-osk-helpers", label: "Toggle On Screen Modifiers" },
+    { value: "rotate-profiles", label: "Rotate Profiles" },
 ];
 
 const DEVICE_OPTIONS = [
@@ -1,8 +1,9 @@
-import { useState } from 'react';
+import { useState, useEffect } from 'react';
 import { Box, Typography, TextField, InputAdornment, IconButton, Chip, Tooltip, Button, ToggleButtonGroup, ToggleButton, Dialog, DialogTitle, DialogContent, DialogActions } from '@mui/material';
 import SearchIcon from '@mui/icons-material/Search';
 import KeyboardIcon from '@mui/icons-material/Keyboard';
 import MouseIcon from '@mui/icons-material/Mouse';
+import DevicesIcon from '@mui/icons-material/Devices';
 import DeleteOutlineIcon from '@mui/icons-material/DeleteOutline';
 import CheckCircleIcon from '@mui/icons-material/CheckCircle';
 import PersonIcon from '@mui/icons-material/Person';
@@ -15,9 +16,23 @@ import FileOpenIcon from '@mui/icons-material/FileOpen';
 import IosShareIcon from '@mui/icons-material/IosShare';
 import ShopTwoIcon from '@mui/icons-material/ShopTwo';
 import AddIcon from '@mui/icons-material/Add';
+import VerifiedUserIcon from '@mui/icons-material/VerifiedUser';
+import GppMaybeIcon from '@mui/icons-material/GppMaybe';
+import StorageIcon from '@mui/icons-material/Storage';
+import SystemUpdateAltIcon from '@mui/icons-material/SystemUpdateAlt';
 import { Card, CardContent } from '@mui/material';
 import { PageHeader } from '../components/common';
 import { glassCardStyle } from '../constants';
+import {
+  Search,
+  GetDiskUsage,
+  CleanupProfile,
+  CleanupAllProfiles,
+  GetBundledProfilesReport,
+  DismissBundledProfilesReport,
+  RestoreBundledProfile,
+} from '../../wailsjs/go/app/Library';
+import { BrowserOpenURL } from '../../wailsjs/runtime/runtime';
 
 function ErrorDialog({ open, onClose, errorMessage }) {
   return (
@@ -98,6 +113,410 @@ function ErrorDialog({ open, onClose, errorMessage }) {
   );
 }
 
+function bundledProfileLabel(change) {
+  if (change.fromVersion && change.toVersion && change.fromVersion !== change.toVersion) {
+    return `${change.name} (${change.fromVersion} → ${change.toVersion})`;
+  }
+  return change.name;
+}
+
+function BundledProfilesBanner() {
+  const [report, setReport] = useState(null);
+  const [restoring, setRestoring] = useState(null);
+
+  const loadReport = () =>
+    GetBundledProfilesReport()
+      .then(setReport)
+      .catch((error) => {
+        console.error('Failed to load bundled profiles report:', error);
+      });
+
+  useEffect(() => {
+    loadReport();
+  }, []);
+
+  if (!report || (report.updated.length === 0 && report.kept.length === 0)) {
+    return null;
+  }
+
+  const dismiss = () => {
+    DismissBundledProfilesReport()
+      .catch((error) => {
+        console.error('Failed to dismiss bundled profiles report:', error);
+      })
+      .finally(loadReport);
+  };
+
+  const restore = (id) => {
+    setRestoring(id);
+    RestoreBundledProfile(id)
+      .catch((error) => {
+        console.error('Failed to restore bundled profile:', error);
+      })
+      .finally(() => {
+        setRestoring(null);
+        loadReport();
+      });
+  };
+
+  return (
+    <Box sx={{ ...glassCardStyle, padding: '16px 20px', marginBottom: '24px' }}>
+      <Box sx={{ display: 'flex', alignItems: 'center', gap: '10px', marginBottom: '8px' }}>
+        <SystemUpdateAltIcon sx={{ color: 'var(--accent-primary)', fontSize: '20px' }} />
+        <Typography sx={{ color: 'var(--text-primary)', fontSize: '15px', fontWeight: 600, flexGrow: 1 }}>
+          Bundled profiles updated
+        </Typography>
+        <Button
+          size="small"
+          onClick={dismiss}
+          sx={{ color: 'var(--text-secondary)', textTransform: 'none', fontSize: '13px' }}
+        >
+          Dismiss
+        </Button>
+      </Box>
+      {report.installed.length > 0 && (
+        <Typography sx={{ color: 'var(--text-secondary)', fontSize: '13px', marginBottom: '4px' }}>
+          Added: {report.installed.map(bundledProfileLabel).join(', ')}
+        </Typography>
+      )}
+      {report.updated.length > 0 && (
+        <Typography sx={{ color: 'var(--text-secondary)', fontSize: '13px', marginBottom: '4px' }}>
+          Updated: {report.updated.map(bundledProfileLabel).join(', ')}
+        </Typography>
+      )}
+      {report.kept.length > 0 && (
+        <>
+          <Typography sx={{ color: 'var(--text-secondary)', fontSize: '13px', marginBottom: '8px' }}>
+            These profiles have a new version but were kept because you changed them. Using the new version
+            discards your changes.
+          </Typography>
+          <Box sx={{ display: 'flex', flexDirection: 'column', gap: '6px' }}>
+            {report.kept.map((change) => (
+              <Box key={change.id} sx={{ display: 'flex', alignItems: 'center', gap: '12px' }}>
+                <Typography sx={{ color: 'var(--text-primary)', fontSize: '13px', flexGrow: 1 }}>
+                  {bundledProfileLabel(change)}
+                </Typography>
+                <Button
+                  size="small"
+                  disabled={restoring !== null}
+                  onClick={() => restore(change.id)}
+                  sx={{ color: 'var(--accent-primary)', textTransform: 'none', fontSize: '13px' }}
+                >
+                  {restoring === change.id ? 'Restoring...' : 'Use New Version'}
+                </Button>
+              </Box>
+            ))}
+          </Box>
+        </>
+      )}
+    </Box>
+  );
+}
+
+function formatBytes(bytes) {
+  if (bytes < 1024) return `${bytes} B`;
+  if (bytes < 1024 * 1024) return `${(bytes / 1024).toFixed(1)} KB`;
+  return `${(bytes / (1024 * 1024)).toFixed(1)} MB`;
+}
+
+function DiskUsageDialog({ open, onClose }) {
+  const [usage, setUsage] = useState([]);
+  const [isCleaning, setIsCleaning] = useState(false);
+  const [cleanupMessage, setCleanupMessage] = useState('');
+
+  const loadUsage = () =>
+    GetDiskUsage()
+      .then((results) => setUsage(results || []))
+      .catch((error) => {
+        console.error('Failed to load disk usage:', error);
+      });
+
+  useEffect(() => {
+    if (open) {
+      setCleanupMessage('');
+      loadUsage();
+    }
+  }, [open]);
+
+  const runCleanup = (cleanup) => {
+    setIsCleaning(true);
+    cleanup()
+      .then((result) => {
+        const count = result.removed?.length || 0;
+        setCleanupMessage(
+          count === 0
+            ? 'No unreferenced files were found.'
+            : `Removed ${count} unreferenced ${count === 1 ? 'file' : 'files'}, freeing ${formatBytes(result.removedBytes)}.`,
+        );
+      })
+      .catch((error) => {
+        console.error('Failed to clean up profiles:', error);
+        setCleanupMessage(`Failed to clean up: ${error}`);
+      })
+      .finally(() => {
+        setIsCleaning(false);
+        loadUsage();
+      });
+  };
+
+  const totalBytes = usage.reduce((sum, u) => sum + u.totalBytes, 0);
+  const orphanedBytes = usage.reduce((sum, u) => sum + u.orphanedBytes, 0);
+  const hasOrphans = usage.some((u) => u.orphanedFiles.length > 0);
+
+  return (
+    <Dialog
+      open={open}
+      onClose={onClose}
+      PaperProps={{
+        sx: {
+          backgroundColor: 'var(--card-bg)',
+          backdropFilter: 'blur(25px)',
+          borderRadius: '16px',
+          border: '1px solid var(--card-border)',
+          boxShadow: '0 24px 48px rgba(0, 0, 0, 0.4)',
+          minWidth: '480px',
+          maxWidth: '640px',
+        },
+      }}
+    >
+      <DialogTitle
+        sx={{
+          display: 'flex',
+          alignItems: 'center',
+          gap: '12px',
+          padding: '24px 24px 16px',
+          color: 'var(--text-primary)',
+          fontSize: '18px',
+          fontWeight: 600,
+        }}
+      >
+        <Box
+          sx={{
+            display: 'flex',
+            alignItems: 'center',
+            justifyContent: 'center',
+            width: '40px',
+            height: '40px',
+            borderRadius: '12px',
+            backgroundColor: 'var(--accent-bg)',
+            border: '1px solid var(--accent-border)',
+          }}
+        >
+          <StorageIcon sx={{ fontSize: '22px', color: 'var(--accent-primary)' }} />
+        </Box>
+        Disk Usage
+      </DialogTitle>
+      <DialogContent sx={{ padding: '0 24px 24px' }}>
+        <Typography sx={{ color: 'var(--text-secondary)', fontSize: '14px', lineHeight: 1.6, marginBottom: '16px' }}>
+          Profiles use {formatBytes(totalBytes)} in total. Unreferenced audio files use {formatBytes(orphanedBytes)} and
+          can be removed. Missing files are referenced by a profile but do not exist.
+        </Typography>
+        <Box sx={{ display: 'flex', flexDirection: 'column', gap: '8px' }}>
+          {usage.map((u) => (
+            <Box
+              key={u.id}
+              sx={{
+                padding: '10px 14px',
+                borderRadius: '10px',
+                backgroundColor: 'var(--hover-bg-light)',
+              }}
+            >
+              <Box sx={{ display: 'flex', alignItems: 'center', gap: '12px' }}>
+                <Typography sx={{ color: 'var(--text-primary)', fontSize: '14px', fontWeight: 500, flexGrow: 1 }}>
+                  {u.name}
+                </Typography>
+                <Typography sx={{ color: 'var(--text-tertiary)', fontSize: '12px' }}>
+                  {formatBytes(u.totalBytes)} · {u.fileCount} {u.fileCount === 1 ? 'file' : 'files'}
+                </Typography>
+                {u.orphanedFiles.length > 0 && (
+                  <Button
+                    size="small"
+                    disabled={isCleaning}
+                    onClick={() => runCleanup(() => CleanupProfile(u.id))}
+                    sx={{ color: 'var(--accent-primary)', textTransform: 'none', fontSize: '12px' }}
+                  >
+                    Clean Up
+                  </Button>
+                )}
+              </Box>
+              {u.orphanedFiles.length > 0 && (
+                <Typography sx={{ color: '#fbbf24', fontSize: '12px', marginTop: '4px' }}>
+                  Unreferenced: {u.orphanedFiles.map((f) => f.path).join(', ')} ({formatBytes(u.orphanedBytes)})
+                </Typography>
+              )}
+              {u.missingFiles.length > 0 && (
+                <Typography sx={{ color: '#f87171', fontSize: '12px', marginTop: '4px' }}>
+                  Missing: {u.missingFiles.map((f) => `${f.path} (${f.sourceId})`).join(', ')}
+                </Typography>
+              )}
+            </Box>
+          ))}
+        </Box>
+        {cleanupMessage && (
+          <Typography sx={{ color: 'var(--text-secondary)', fontSize: '13px', marginTop: '16px' }}>
+            {cleanupMessage}
+          </Typography>
+        )}
+      </DialogContent>
+      <DialogActions sx={{ padding: '0 24px 24px', gap: '12px' }}>
+        <Button
+          onClick={() => runCleanup(CleanupAllProfiles)}
+          disabled={!hasOrphans || isCleaning}
+          sx={{
+            color: 'var(--text-secondary)',
+            borderRadius: '10px',
+            padding: '8px 20px',
+            fontSize: '14px',
+            fontWeight: 500,
+            textTransform: 'none',
+          }}
+        >
+          Clean Up All
+        </Button>
+        <Button
+          onClick={onClose}
+          variant="contained"
+          sx={{
+            backgroundColor: 'var(--accent-primary)',
+            color: 'white',
+            borderRadius: '10px',
+            padding: '8px 20px',
+            fontSize: '14px',
+            fontWeight: 600,
+            textTransform: 'none',
+            boxShadow: '0 4px 12px var(--accent-shadow)',
+            '&:hover': {
+              backgroundColor: 'var(--accent-secondary)',
+            },
+          }}
+        >
+          Done
+        </Button>
+      </DialogActions>
+    </Dialog>
+  );
+}
+
+function ImportResultDialog({ open, onClose, result }) {
+  const verification = result?.verification;
+  const isTrusted = verification === 'trusted';
+  const isSigned = isTrusted || verification === 'self-signed';
+  const isUnverified = verification === 'none';
+
+  let message;
+  if (isTrusted) {
+    message = 'The archive was signed with a trusted key and none of its files have been modified since it was exported.';
+  } else if (isSigned) {
+    message = 'None of the files in the archive have been modified since it was signed, but the key it was signed with is not trusted, so its author cannot be confirmed.';
+  } else if (verification === 'hashes') {
+    message = 'None of the files in the archive have been modified or corrupted, but the archive was not signed, so its author cannot be confirmed.';
+  } else {
+    message = 'The archive has no manifest, so its files could not be verified. It may have been exported by an older version.';
+  }
+
+  return (
+    <Dialog
+      open={open}
+      onClose={onClose}
+      PaperProps={{
+        sx: {
+          backgroundColor: 'var(--card-bg)',
+          backdropFilter: 'blur(25px)',
+          borderRadius: '16px',
+          border: '1px solid var(--card-border)',
+          boxShadow: '0 24px 48px rgba(0, 0, 0, 0.4)',
+          minWidth: '400px',
+          maxWidth: '480px',
+        },
+      }}
+    >
+      <DialogTitle
+        sx={{
+          display: 'flex',
+          alignItems: 'center',
+          gap: '12px',
+          padding: '24px 24px 16px',
+          color: 'var(--text-primary)',
+          fontSize: '18px',
+          fontWeight: 600,
+        }}
+      >
+        <Box
+          sx={{
+            display: 'flex',
+            alignItems: 'center',
+            justifyContent: 'center',
+            width: '40px',
+            height: '40px',
+            borderRadius: '12px',
+            backgroundColor: isUnverified ? 'rgba(245, 158, 11, 0.15)' : 'var(--accent-bg)',
+            border: isUnverified ? '1px solid rgba(245, 158, 11, 0.3)' : '1px solid var(--accent-border)',
+          }}
+        >
+          {isUnverified ? (
+            <GppMaybeIcon sx={{ fontSize: '22px', color: '#fbbf24' }} />
+          ) : (
+            <VerifiedUserIcon sx={{ fontSize: '22px', color: 'var(--accent-primary)' }} />
+          )}
+        </Box>
+        Imported {result?.profile}
+      </DialogTitle>
+      <DialogContent sx={{ padding: '0 24px 24px' }}>
+        <Typography
+          sx={{
+            color: 'var(--text-secondary)',
+            fontSize: '14px',
+            lineHeight: 1.6,
+          }}
+        >
+          {message}
+        </Typography>
+        {isSigned && (
+          <Box
+            sx={{
+              marginTop: '16px',
+              padding: '12px 16px',
+              borderRadius: '10px',
+              backgroundColor: 'var(--accent-bg)',
+              border: '1px solid var(--accent-border)',
+            }}
+          >
+            <Typography sx={{ color: 'var(--text-primary)', fontSize: '13px', fontWeight: 500 }}>
+              {isTrusted
+                ? `Signed by ${result.signer || 'an unnamed signer'}`
+                : `Self-signed, claims to be ${result.signer || 'an unnamed signer'}`}
+            </Typography>
+            <Typography sx={{ color: 'var(--text-tertiary)', fontSize: '12px', fontFamily: 'monospace', marginTop: '4px' }}>
+              {result.signerFingerprint}
+            </Typography>
+          </Box>
+        )}
+      </DialogContent>
+      <DialogActions sx={{ padding: '0 24px 24px', gap: '12px' }}>
+        <Button
+          onClick={onClose}
+          variant="contained"
+          sx={{
+            backgroundColor: 'var(--accent-primary)',
+            color: 'white',
+            borderRadius: '10px',
+            padding: '8px 20px',
+            fontSize: '14px',
+            fontWeight: 600,
+            textTransform: 'none',
+            boxShadow: '0 4px 12px var(--accent-shadow)',
+            '&:hover': {
+              backgroundColor: 'var(--accent-secondary)',
+            },
+          }}
+        >
+          OK
+        </Button>
+      </DialogActions>
+    </Dialog>
+  );
+}
+
 function DeleteConfirmModal({ open, onClose, onConfirm, profileName, isDeleting }) {
   return (
     <Dialog
@@ -219,10 +638,38 @@ function DeleteConfirmModal({ open, onClose, onConfirm, profileName, isDeleting
   );
 }
 
-function ProfileCard({ profile, isDefault, onRemove, onOpenFolder, onExport, isExiting }) {
-  const isKeyboard = profile.type === 'keyboard';
-  const TypeIcon = isKeyboard ? KeyboardIcon : MouseIcon;
-  const canDelete = !profile.inUse;
+// Label, icon and colors of each profile type. Desk profiles are for both a keyboard and a mouse.
+const profileTypeStyles = {
+  keyboard: {
+    label: 'Keyboard',
+    icon: KeyboardIcon,
+    background: 'rgba(99, 102, 241, 0.15)',
+    border: '1px solid rgba(99, 102, 241, 0.25)',
+    color: '#818cf8',
+  },
+  mouse: {
+    label: 'Mouse',
+    icon: MouseIcon,
+    background: 'rgba(236, 72, 153, 0.15)',
+    border: '1px solid rgba(236, 72, 153, 0.25)',
+    color: '#f472b6',
+  },
+  desk: {
+    label: 'Keyboard + Mouse',
+    icon: DevicesIcon,
+    background: 'rgba(245, 158, 11, 0.15)',
+    border: '1px solid rgba(245, 158, 11, 0.25)',
+    color: '#fbbf24',
+  },
+};
+
+function ProfileCard({ profile, isDefault, onRemove, onOpenFolder, onExport, onTagClick, isExiting }) {
+  const typeStyle = profileTypeStyles[profile.type] ?? profileTypeStyles.keyboard;
+  const TypeIcon = typeStyle.icon;
+  const canDelete = !profile.inUse && !profile.readOnly;
+  const deleteTooltip = profile.readOnly
+    ? "Installed system-wide and cannot be removed"
+    : profile.inUse ? profile.inUseReason : "Remove profile";
 
   return (
     <Card
@@ -262,19 +709,15 @@ function ProfileCard({ profile, isDefault, onRemove, onOpenFolder, onExport, isE
               width: '56px',
               height: '56px',
               borderRadius: '16px',
-              backgroundColor: isKeyboard
-                ? 'rgba(99, 102, 241, 0.15)'
-                : 'rgba(236, 72, 153, 0.15)',
-              border: isKeyboard
-                ? '1px solid rgba(99, 102, 241, 0.25)'
-                : '1px solid rgba(236, 72, 153, 0.25)',
+              backgroundColor: typeStyle.background,
+              border: typeStyle.border,
               flexShrink: 0,
             }}
           >
             <TypeIcon
               sx={{
                 fontSize: '28px',
-                color: isKeyboard ? '#818cf8' : '#f472b6',
+                color: typeStyle.color,
               }}
             />
           </Box>
@@ -292,7 +735,7 @@ function ProfileCard({ profile, isDefault, onRemove, onOpenFolder, onExport, isE
                   letterSpacing: '-0.01em',
                 }}
               >
-                {profile.name}
+                {profile.displayName || profile.name}
               </Typography>
               {isDefault && (
                 <Chip
@@ -330,6 +773,46 @@ function ProfileCard({ profile, isDefault, onRemove, onOpenFolder, onExport, isE
                   }}
                 />
               )}
+              {profile.errors?.length > 0 && (
+                <Tooltip title={profile.errors.join('\n')} arrow slotProps={{ tooltip: { sx: { whiteSpace: 'pre-line' } } }}>
+                  <Chip
+                    icon={<ErrorOutlineIcon sx={{ fontSize: '12px !important' }} />}
+                    label={profile.errors.length === 1 ? '1 Error' : `${profile.errors.length} Errors`}
+                    size="small"
+                    sx={{
+                      backgroundColor: 'rgba(239, 68, 68, 0.15)',
+                      color: '#f87171',
+                      border: '1px solid rgba(239, 68, 68, 0.3)',
+                      fontSize: '10px',
+                      fontWeight: 500,
+                      height: '22px',
+                      '& .MuiChip-icon': {
+                        color: '#f87171',
+                      },
+                    }}
+                  />
+                </Tooltip>
+              )}
+              {profile.warnings?.length > 0 && (
+                <Tooltip title={profile.warnings.join('\n')} arrow slotProps={{ tooltip: { sx: { whiteSpace: 'pre-line' } } }}>
+                  <Chip
+                    icon={<WarningAmberIcon sx={{ fontSize: '12px !important' }} />}
+                    label={profile.warnings.length === 1 ? '1 Warning' : `${profile.warnings.length} Warnings`}
+                    size="small"
+                    sx={{
+                      backgroundColor: 'rgba(245, 158, 11, 0.15)',
+                      color: '#fbbf24',
+                      border: '1px solid rgba(245, 158, 11, 0.3)',
+                      fontSize: '10px',
+                      fontWeight: 500,
+                      height: '22px',
+                      '& .MuiChip-icon': {
+                        color: '#fbbf24',
+                      },
+                    }}
+                  />
+                </Tooltip>
+              )}
             </Box>
 
             {/* Description */}
@@ -362,7 +845,7 @@ function ProfileCard({ profile, isDefault, onRemove, onOpenFolder, onExport, isE
                 </Typography>
               </Box>
               <Chip
-                label={isKeyboard ? 'Keyboard' : 'Mouse'}
+                label={typeStyle.label}
                 size="small"
                 sx={{
                   backgroundColor: 'var(--hover-bg-light)',
@@ -372,6 +855,52 @@ function ProfileCard({ profile, isDefault, onRemove, onOpenFolder, onExport, isE
                   border: '1px solid var(--card-border)',
                 }}
               />
+              {profile.switchType && (
+                <Typography sx={{ color: 'var(--text-muted)', fontSize: '12px' }}>
+                  {profile.switchType}
+                </Typography>
+              )}
+              {profile.version && (
+                <Typography sx={{ color: 'var(--text-muted)', fontSize: '12px' }}>
+                  v{profile.version}
+                </Typography>
+              )}
+              {profile.license && (
+                <Typography sx={{ color: 'var(--text-muted)', fontSize: '12px' }}>
+                  {profile.license}
+                </Typography>
+              )}
+              {profile.homepage && (
+                <Typography
+                  onClick={() => BrowserOpenURL(profile.homepage)}
+                  sx={{
+                    color: 'var(--accent-primary)',
+                    fontSize: '12px',
+                    cursor: 'pointer',
+                    '&:hover': { textDecoration: 'underline' },
+                  }}
+                >
+                  Homepage
+                </Typography>
+              )}
+              {profile.tags?.map((tag) => (
+                <Chip
+                  key={tag}
+                  label={tag}
+                  size="small"
+                  onClick={() => onTagClick && onTagClick(tag)}
+                  sx={{
+                    backgroundColor: 'rgba(99, 102, 241, 0.1)',
+                    color: '#818cf8',
+                    fontSize: '10px',
+                    height: '20px',
+                    border: '1px solid rgba(99, 102, 241, 0.25)',
+                    '&:hover': {
+                      backgroundColor: 'rgba(99, 102, 241, 0.2)',
+                    },
+                  }}
+                />
+              ))}
             </Box>
           </Box>
 
@@ -420,7 +949,7 @@ function ProfileCard({ profile, isDefault, onRemove, onOpenFolder, onExport, isE
               </IconButton>
             </Tooltip>
             <Tooltip
-              title={!canDelete ? profile.inUseReason : "Remove profile"}
+              title={deleteTooltip}
               arrow
               placement="top"
             >
@@ -520,6 +1049,7 @@ function StatCard({ icon: Icon, count, label, color, gradient }) {
 function LibraryPage({
   keyboardProfiles,
   mouseProfiles,
+  deskProfiles = [],
   defaultKeyboardProfile,
   defaultMouseProfile,
   searchQuery,
@@ -533,12 +1063,16 @@ function LibraryPage({
 }) {
   const [typeFilter, setTypeFilter] = useState('all');
   const [sortBy, setSortBy] = useState('name');
+  const [tagFilter, setTagFilter] = useState([]);
+  const [searchResults, setSearchResults] = useState(null);
   const [deleteModalOpen, setDeleteModalOpen] = useState(false);
   const [profileToDelete, setProfileToDelete] = useState(null);
   const [isDeleting, setIsDeleting] = useState(false);
   const [exitingProfileId, setExitingProfileId] = useState(null);
   const [errorDialogOpen, setErrorDialogOpen] = useState(false);
   const [errorMessage, setErrorMessage] = useState('');
+  const [importResult, setImportResult] = useState(null);
+  const [diskUsageOpen, setDiskUsageOpen] = useState(false);
 
   // Handle delete request - show confirmation modal
   const handleDeleteRequest = (profile) => {
@@ -581,7 +1115,10 @@ function LibraryPage({
   // Handle import with error dialog
   const handleImport = async () => {
     try {
-      await onImportProfile();
+      const result = await onImportProfile();
+      if (result) {
+        setImportResult(result);
+      }
     } catch (error) {
       console.error('Failed to import profile:', error);
       const message = error?.message || error?.toString() || 'Failed to import profile. Please try again.';
@@ -608,52 +1145,62 @@ function LibraryPage({
     setErrorMessage('');
   };
 
-  // Combine and filter profiles
+  // Combine profiles
   const allProfiles = [
     ...keyboardProfiles.map(p => ({ ...p, type: 'keyboard' })),
     ...mouseProfiles.map(p => ({ ...p, type: 'mouse' })),
+    ...deskProfiles.map(p => ({ ...p, type: 'desk' })),
   ];
 
-  // Apply type filter
-  let filteredProfiles = typeFilter === 'all' 
-    ? allProfiles 
-    : allProfiles.filter(p => p.type === typeFilter);
-
-  // Apply search filter
-  if (searchQuery) {
-    const query = searchQuery.toLowerCase();
-    filteredProfiles = filteredProfiles.filter((profile) =>
-      profile.name.toLowerCase().includes(query) ||
-      (profile.description && profile.description.toLowerCase().includes(query)) ||
-      (profile.author && profile.author.toLowerCase().includes(query))
-    );
-  }
+  // Search, filter and sort in the backend. Re-run whenever the profiles change so the results stay current.
+  useEffect(() => {
+    let cancelled = false;
+    Search(searchQuery || '', {
+      type: typeFilter === 'all' ? '' : typeFilter,
+      tags: tagFilter,
+      author: '',
+      sortBy,
+    })
+      .then((results) => {
+        if (!cancelled) {
+          setSearchResults(results || []);
+        }
+      })
+      .catch((error) => {
+        console.error('Failed to search profiles:', error);
+      });
+    return () => {
+      cancelled = true;
+    };
+  }, [searchQuery, typeFilter, tagFilter, sortBy, keyboardProfiles, mouseProfiles, deskProfiles]);
 
-  // Apply sort
-  filteredProfiles = [...filteredProfiles].sort((a, b) => {
-    switch (sortBy) {
-      case 'name':
-        return a.name.localeCompare(b.name);
-      case 'author':
-        return (a.author || '').localeCompare(b.author || '');
-      case 'type':
-        return a.type.localeCompare(b.type);
-      default:
-        return 0;
-    }
-  });
+  // Until the first search completes, show the unfiltered profiles
+  const filteredProfiles = searchResults ?? allProfiles;
+
+  // Toggle a tag in the tag filter
+  const handleTagClick = (tag) => {
+    setTagFilter((current) =>
+      current.some((t) => t.toLowerCase() === tag.toLowerCase())
+        ? current.filter((t) => t.toLowerCase() !== tag.toLowerCase())
+        : [...current, tag]
+    );
+  };
 
   const isDefaultProfile = (profile) => {
     if (profile.type === 'keyboard') {
       return profile.name === defaultKeyboardProfile;
     }
+    if (profile.type === 'desk') {
+      return profile.name === defaultKeyboardProfile || profile.name === defaultMouseProfile;
+    }
     return profile.name === defaultMouseProfile;
   };
 
   // Get counts for badges
   const keyboardCount = keyboardProfiles.length;
   const mouseCount = mouseProfiles.length;
-  const totalCount = keyboardCount + mouseCount;
+  const deskCount = deskProfiles.length;
+  const totalCount = keyboardCount + mouseCount + deskCount;
 
   if (isLoading) {
     return (
@@ -779,6 +1326,27 @@ function LibraryPage({
               <FileOpenIcon />
             </IconButton>
           </Tooltip>
+          <Tooltip title="Disk Usage" arrow placement="top">
+            <IconButton
+              onClick={() => setDiskUsageOpen(true)}
+              sx={{
+                background: 'linear-gradient(135deg, var(--accent-primary) 0%, var(--accent-secondary) 100%)',
+                color: 'white',
+                width: '40px',
+                height: '40px',
+                borderRadius: '12px',
+                boxShadow: '0 4px 12px var(--accent-shadow)',
+                '&:hover': {
+                  background: 'linear-gradient(135deg, var(--accent-secondary) 0%, var(--accent-light) 100%)',
+                  boxShadow: '0 6px 16px var(--accent-shadow)',
+                  transform: 'translateY(-1px)',
+                },
+                transition: 'all 0.2s cubic-bezier(0.4, 0, 0.2, 1)',
+              }}
+            >
+              <StorageIcon />
+            </IconButton>
+          </Tooltip>
         </Box>
       </PageHeader>
 
@@ -804,8 +1372,19 @@ function LibraryPage({
           color="#f472b6"
           gradient="linear-gradient(135deg, rgba(236, 72, 153, 0.08) 0%, var(--card-bg) 100%)"
         />
+        {deskCount > 0 && (
+          <StatCard
+            icon={DevicesIcon}
+            count={deskCount}
+            label="Desk Profiles"
+            color="#fbbf24"
+            gradient="linear-gradient(135deg, rgba(245, 158, 11, 0.08) 0%, var(--card-bg) 100%)"
+          />
+        )}
       </Box>
 
+      <BundledProfilesBanner />
+
       {/* Empty State - No profiles at all */}
       {totalCount === 0 ? (
         <Box
@@ -904,6 +1483,12 @@ function LibraryPage({
                 <MouseIcon sx={{ fontSize: '16px', marginRight: '6px' }} />
                 Mouse ({mouseCount})
               </ToggleButton>
+              {deskCount > 0 && (
+                <ToggleButton value="desk">
+                  <DevicesIcon sx={{ fontSize: '16px', marginRight: '6px' }} />
+                  Desk ({deskCount})
+                </ToggleButton>
+              )}
             </ToggleButtonGroup>
 
             {/* Sort dropdown */}
@@ -938,6 +1523,7 @@ function LibraryPage({
                   },
                 }}
               >
+                {searchQuery && <ToggleButton value="relevance">Relevance</ToggleButton>}
                 <ToggleButton value="name">Name</ToggleButton>
                 <ToggleButton value="author">Author</ToggleButton>
                 <ToggleButton value="type">Type</ToggleButton>
@@ -945,6 +1531,32 @@ function LibraryPage({
             </Box>
           </Box>
 
+          {/* Active tag filters */}
+          {tagFilter.length > 0 && (
+            <Box sx={{ display: 'flex', alignItems: 'center', gap: '8px', marginBottom: '16px', flexWrap: 'wrap' }}>
+              <Typography sx={{ color: 'var(--text-muted)', fontSize: '13px' }}>
+                Tags:
+              </Typography>
+              {tagFilter.map((tag) => (
+                <Chip
+                  key={tag}
+                  label={tag}
+                  size="small"
+                  onDelete={() => handleTagClick(tag)}
+                  sx={{
+                    backgroundColor: 'rgba(99, 102, 241, 0.2)',
+                    color: '#818cf8',
+                    fontSize: '12px',
+                    border: '1px solid rgba(99, 102, 241, 0.4)',
+                    '& .MuiChip-deleteIcon': {
+                      color: '#818cf8',
+                    },
+                  }}
+                />
+              ))}
+            </Box>
+          )}
+
           {/* Profile List */}
           {filteredProfiles.length === 0 ? (
             <Box
@@ -961,7 +1573,7 @@ function LibraryPage({
                   fontSize: '15px',
                 }}
               >
-                No profiles found matching "{searchQuery}"
+                {searchQuery ? `No profiles found matching "${searchQuery}"` : 'No profiles found'}
               </Typography>
             </Box>
           ) : (
@@ -974,6 +1586,7 @@ function LibraryPage({
                   onRemove={() => handleDeleteRequest(profile)}
                   onOpenFolder={onOpenProfileFolder}
                   onExport={onExportProfile}
+                  onTagClick={handleTagClick}
                   isExiting={exitingProfileId === profile.id}
                 />
               ))}
@@ -998,6 +1611,14 @@ function LibraryPage({
         onClose={handleCloseErrorDialog}
         errorMessage={errorMessage}
       />
+
+      <ImportResultDialog
+        open={importResult !== null}
+        onClose={() => setImportResult(null)}
+        result={importResult}
+      />
+
+      <DiskUsageDialog open={diskUsageOpen} onClose={() => setDiskUsageOpen(false)} />
     </Box>
   );
 }
@@ -21,10 +21,12 @@ import ContrastIcon from "@mui/icons-material/Contrast";
 import RefreshIcon from "@mui/icons-material/Refresh";
 import DownloadIcon from "@mui/icons-material/Download";
 import { GlassCard, PageHeader } from "../components/common";
+import { ProfileRotationCard } from "../components/settings";
 import {
     greenSwitchStyle,
     selectMenuProps,
     enabledSoundOptions,
+    keyRepeatOptions,
 } from "../constants";
 import { useTheme } from "../context";
 import { GetVersion } from "../../wailsjs/go/main/wailsConfig";
@@ -39,6 +41,16 @@ import {
     GetClickOverlayShowsApp,
     SetClickOverlayShowsApp,
 } from "../../wailsjs/go/app/OSKHelperBinding";
+import {
+    GetSigningIdentity,
+    SetExportSigner,
+    GetProfileLocale,
+    SetProfileLocale,
+} from "../../wailsjs/go/app/Library";
+import {
+    GetKeyRepeat,
+    SetKeyRepeat,
+} from "../../wailsjs/go/app/AudioEffects";
 
 const THEME_OPTIONS = [
     { id: "dark-modern", label: "Modern Dark", Icon: ContrastIcon },
@@ -46,6 +58,18 @@ const THEME_OPTIONS = [
     { id: "light", label: "Light", Icon: LightModeIcon },
 ];
 
+/** Returns the name of a locale in its own language, such as "Deutsch" for "de". */
+function localeLabel(locale) {
+    try {
+        const name = new Intl.DisplayNames([locale], { type: "language" }).of(
+            locale,
+        );
+        return name ? `${name} (${locale})` : locale;
+    } catch {
+        return locale;
+    }
+}
+
 function inAppProfileSelectSx(hasProfile) {
     return {
         backgroundColor: hasProfile ? "var(--accent-bg)" : "var(--input-bg)",
@@ -121,6 +145,15 @@ function SettingsPage({
     const [isRefreshing, setIsRefreshing] = useState(false);
     const [oskClickShowsApp, setOskClickShowsApp] = useState(true);
     const [inAppSoundTestText, setInAppSoundTestText] = useState("");
+    const [exportSigner, setExportSigner] = useState("");
+    const [signingFingerprint, setSigningFingerprint] = useState("");
+    const [keyRepeatMode, setKeyRepeatMode] = useState("off");
+    const [keyRepeatMaxRate, setKeyRepeatMaxRate] = useState(15);
+    const [profileLocale, setProfileLocale] = useState({
+        locale: "",
+        effective: "",
+        available: [],
+    });
 
     const refreshUpdateInfo = useCallback(async () => {
         setIsRefreshing(true);
@@ -148,6 +181,64 @@ function SettingsPage({
         refreshUpdateInfo();
     }, [refreshUpdateInfo]);
 
+    useEffect(() => {
+        GetSigningIdentity()
+            .then((identity) => {
+                setExportSigner(identity.signer);
+                setSigningFingerprint(identity.fingerprint);
+            })
+            .catch((err) => {
+                console.error("Failed to load signing identity:", err);
+            });
+    }, []);
+
+    useEffect(() => {
+        GetKeyRepeat()
+            .then((state) => {
+                setKeyRepeatMode(state.mode);
+                setKeyRepeatMaxRate(state.maxRate);
+            })
+            .catch((err) => {
+                console.error("Failed to load key repeat settings:", err);
+            });
+    }, []);
+
+    const saveKeyRepeat = (mode, maxRate) => {
+        SetKeyRepeat(mode, Number(maxRate))
+            .then(() => GetKeyRepeat())
+            .then((state) => {
+                setKeyRepeatMode(state.mode);
+                setKeyRepeatMaxRate(state.maxRate);
+            })
+            .catch((err) => {
+                console.error("Failed to set key repeat settings:", err);
+            });
+    };
+
+    const loadProfileLocale = () =>
+        GetProfileLocale()
+            .then((state) =>
+                setProfileLocale({
+                    ...state,
+                    available: state.available || [],
+                }),
+            )
+            .catch((err) => {
+                console.error("Failed to load profile language:", err);
+            });
+
+    useEffect(() => {
+        loadProfileLocale();
+    }, []);
+
+    const saveProfileLocale = (locale) => {
+        SetProfileLocale(locale)
+            .catch((err) => {
+                console.error("Failed to set profile language:", err);
+            })
+            .finally(loadProfileLocale);
+    };
+
     useEffect(() => {
         if (!isMacOS) {
             return;
@@ -418,6 +509,61 @@ function SettingsPage({
                         />
                     </Box>
                 )}
+
+                <Box
+                    sx={{
+                        display: "flex",
+                        justifyContent: "space-between",
+                        alignItems: "center",
+                        marginTop: "20px",
+                        gap: "16px",
+                    }}
+                >
+                    <Box sx={{ flexGrow: 1, marginRight: "8px" }}>
+                        <Typography
+                            sx={{
+                                color: "var(--text-primary)",
+                                fontSize: "15px",
+                                fontWeight: 500,
+                                marginBottom: "4px",
+                            }}
+                        >
+                            Profile Language
+                        </Typography>
+                        <Typography
+                            sx={{
+                                color: "var(--text-tertiary)",
+                                fontSize: "13px",
+                            }}
+                        >
+                            Show translated profile names and descriptions in
+                            the library when a profile provides them
+                        </Typography>
+                    </Box>
+                    <FormControl size="small" sx={{ minWidth: 128 }}>
+                        <Select
+                            value={profileLocale.locale}
+                            onChange={(e) => saveProfileLocale(e.target.value)}
+                            displayEmpty
+                            MenuProps={selectMenuProps}
+                            sx={inAppProfileSelectSx(false)}
+                        >
+                            <MenuItem value="">
+                                {profileLocale.effective
+                                    ? `System (${profileLocale.effective})`
+                                    : "System"}
+                            </MenuItem>
+                            {profileLocale.available
+                                .filter((locale) => locale !== profileLocale.locale)
+                                .concat(profileLocale.locale ? [profileLocale.locale] : [])
+                                .map((locale) => (
+                                    <MenuItem key={locale} value={locale}>
+                                        {localeLabel(locale)}
+                                    </MenuItem>
+                                ))}
+                        </Select>
+                    </FormControl>
+                </Box>
             </GlassCard>
 
             {/* Application Settings Section */}
@@ -1071,6 +1217,230 @@ function SettingsPage({
                 </GlassCard>
             )}
 
+            {/* Profile Rotation Section */}
+            <ProfileRotationCard
+                keyboardProfiles={keyboardProfiles}
+                mouseProfiles={mouseProfiles}
+            />
+
+            {/* Key Repeat Section */}
+            <GlassCard sx={{ marginBottom: "24px" }}>
+                <Typography
+                    variant="h6"
+                    sx={{
+                        color: "var(--text-primary)",
+                        fontSize: "18px",
+                        fontWeight: 600,
+                        marginBottom: "24px",
+                    }}
+                >
+                    Key Repeat
+                </Typography>
+                <Box
+                    sx={{
+                        display: "flex",
+                        justifyContent: "space-between",
+                        alignItems: "center",
+                        gap: "16px",
+                    }}
+                >
+                    <Box sx={{ flexGrow: 1, marginRight: "8px" }}>
+                        <Typography
+                            sx={{
+                                color: "var(--text-primary)",
+                                fontSize: "15px",
+                                fontWeight: 500,
+                                marginBottom: "4px",
+                            }}
+                        >
+                            Held Key Sounds
+                        </Typography>
+                        <Typography
+                            sx={{
+                                color: "var(--text-tertiary)",
+                                fontSize: "13px",
+                            }}
+                        >
+                            Choose whether sounds play for the repeated key
+                            presses sent while a key is held down, such as when
+                            holding Backspace
+                        </Typography>
+                    </Box>
+                    <Box
+                        sx={{
+                            display: "flex",
+                            alignItems: "center",
+                            gap: "8px",
+                            flexShrink: 0,
+                        }}
+                    >
+                        {keyRepeatMode === "throttled" && (
+                            <TextField
+                                size="small"
+                                type="number"
+                                value={keyRepeatMaxRate}
+                                onChange={(e) =>
+                                    setKeyRepeatMaxRate(e.target.value)
+                                }
+                                onBlur={() =>
+                                    saveKeyRepeat(
+                                        keyRepeatMode,
+                                        keyRepeatMaxRate,
+                                    )
+                                }
+                                inputProps={{
+                                    min: 1,
+                                    max: 60,
+                                    "aria-label": "Maximum repeat sounds per second",
+                                }}
+                                InputProps={{
+                                    endAdornment: (
+                                        <Typography
+                                            sx={{
+                                                color: "var(--text-tertiary)",
+                                                fontSize: "12px",
+                                                marginLeft: "4px",
+                                            }}
+                                        >
+                                            /s
+                                        </Typography>
+                                    ),
+                                }}
+                                sx={{
+                                    width: "96px",
+                                    "& .MuiOutlinedInput-root": {
+                                        backgroundColor: "var(--input-bg)",
+                                        borderRadius: "8px",
+                                        fontSize: "13px",
+                                        color: "var(--text-primary)",
+                                        "& fieldset": {
+                                            borderColor: "var(--input-border)",
+                                        },
+                                        "&:hover fieldset": {
+                                            borderColor: "var(--accent-primary)",
+                                        },
+                                        "&.Mui-focused fieldset": {
+                                            borderColor: "var(--accent-primary)",
+                                        },
+                                    },
+                                }}
+                            />
+                        )}
+                        <FormControl size="small" sx={{ minWidth: 128 }}>
+                            <Select
+                                value={keyRepeatMode}
+                                onChange={(e) =>
+                                    saveKeyRepeat(
+                                        e.target.value,
+                                        keyRepeatMaxRate,
+                                    )
+                                }
+                                MenuProps={selectMenuProps}
+                                sx={inAppProfileSelectSx(false)}
+                            >
+                                {keyRepeatOptions.map(({ value, label }) => (
+                                    <MenuItem key={value} value={value}>
+                                        {label}
+                                    </MenuItem>
+                                ))}
+                            </Select>
+                        </FormControl>
+                    </Box>
+                </Box>
+            </GlassCard>
+
+            {/* Profile Sharing Section */}
+            <GlassCard sx={{ marginBottom: "24px" }}>
+                <Typography
+                    variant="h6"
+                    sx={{
+                        color: "var(--text-primary)",
+                        fontSize: "18px",
+                        fontWeight: 600,
+                        marginBottom: "24px",
+                    }}
+                >
+                    Profile Sharing
+                </Typography>
+                <Box
+                    sx={{
+                        display: "flex",
+                        justifyContent: "space-between",
+                        alignItems: "center",
+                        gap: "16px",
+                    }}
+                >
+                    <Box sx={{ flexGrow: 1, marginRight: "8px" }}>
+                        <Typography
+                            sx={{
+                                color: "var(--text-primary)",
+                                fontSize: "15px",
+                                fontWeight: 500,
+                                marginBottom: "4px",
+                            }}
+                        >
+                            Sign Exported Profiles
+                        </Typography>
+                        <Typography
+                            sx={{
+                                color: "var(--text-tertiary)",
+                                fontSize: "13px",
+                            }}
+                        >
+                            When a name is set, exported profiles are signed so
+                            others can confirm who exported them. Leave empty to
+                            export unsigned profiles.
+                        </Typography>
+                        {signingFingerprint && (
+                            <Typography
+                                sx={{
+                                    color: "var(--text-tertiary)",
+                                    fontSize: "12px",
+                                    fontFamily: "monospace",
+                                    marginTop: "6px",
+                                }}
+                            >
+                                Key fingerprint: {signingFingerprint}
+                            </Typography>
+                        )}
+                    </Box>
+                    <TextField
+                        size="small"
+                        placeholder="Your name"
+                        value={exportSigner}
+                        onChange={(e) => setExportSigner(e.target.value)}
+                        onBlur={() => {
+                            SetExportSigner(exportSigner).catch((err) => {
+                                console.error(
+                                    "Failed to set export signer:",
+                                    err,
+                                );
+                            });
+                        }}
+                        inputProps={{ "aria-label": "Export signer name" }}
+                        sx={{
+                            width: "200px",
+                            flexShrink: 0,
+                            "& .MuiOutlinedInput-root": {
+                                backgroundColor: "var(--input-bg)",
+                                borderRadius: "10px",
+                                fontSize: "13px",
+                                color: "var(--text-primary)",
+                                "& fieldset": {
+                                    borderColor: "var(--input-border)",
+                                },
+                                "&:hover fieldset": {
+                                    borderColor: "var(--accent-primary)",
+                                },
+                                "&.Mui-focused fieldset": {
+                                    borderColor: "var(--accent-primary)",
+                                },
+                            },
+                        }}
+                    />
+                </Box>
+            </GlassCard>
+
             {/* Application Details Section */}
             <GlassCard>
                 <Box
@@ -10,7 +10,6 @@ require (
 	github.com/jackmordaunt/icns/v3 v3.0.1
 	github.com/keyboard-sounds/keyboardsounds-pro/backend v0.3.2
 	github.com/nfnt/resize v0.0.0-20180221191011-83c6a9932646
-	github.com/otiai10/copy v1.14.1
 	github.com/wailsapp/wails/v2 v2.12.0
 	gopkg.in/yaml.v2 v2.4.0
 	howett.net/plist v1.0.2-0.20250314012144-ee69052608d9
@@ -37,7 +36,6 @@ require (
 	github.com/leaanthony/u v1.1.1 // indirect
 	github.com/mattn/go-colorable v0.1.13 // indirect
 	github.com/mattn/go-isatty v0.0.20 // indirect
-	github.com/otiai10/mint v1.6.3 // indirect
 	github.com/pkg/browser v0.0.0-20240102092130-5ac0b6a4141c // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/rivo/uniseg v0.4.7 // indirect
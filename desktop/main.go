@@ -361,6 +361,9 @@ func main() {
 				}
 			}
 		},
+		OnShutdown: func(ctx context.Context) {
+			app.Shutdown()
+		},
 		OnBeforeClose: func(ctx context.Context) (prevent bool) {
 			if isFedora || rt.GOOS == "darwin" {
 				return false // Allow close: quit the application